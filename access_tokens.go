@@ -0,0 +1,172 @@
+package tgit
+
+import (
+	"errors"
+	"net/http"
+)
+
+type PersonalAccessToken struct {
+	ID          int64    `json:"id"`
+	UserID      int64    `json:"user_id"`
+	Name        string   `json:"name"`
+	Scopes      []string `json:"scopes"`
+	Active      bool     `json:"active"`
+	Revoked     bool     `json:"revoked"`
+	Impersonate bool     `json:"impersonation"`
+	CreatedAt   *Time    `json:"created_at"`
+	ExpiresAt   *Time    `json:"expires_at"`
+
+	// Token is only returned when the token is created.
+	Token string `json:"token"`
+}
+
+func (t PersonalAccessToken) String() string {
+	return Stringify(t)
+}
+
+type ListPersonalAccessTokensOptions struct {
+	ListOptions
+	State *string `url:"state,omitempty" json:"state,omitempty"`
+}
+
+// ListPersonalAccessTokens lists the tokens of the authenticated user.
+// tgit doc: https://code.tencent.com/help/api/user#listPersonalAccessTokens
+func (s *UsersService) ListPersonalAccessTokens(opts *ListPersonalAccessTokensOptions) ([]*PersonalAccessToken, *Response, error) {
+	req, err := s.client.newRequest(http.MethodGet, newRoute("user/personal_access_tokens"), opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var t []*PersonalAccessToken
+	resp, err := s.client.Do(req, &t)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return t, resp, err
+}
+
+type CreatePersonalAccessTokenOptions struct {
+	Name   *string  `url:"name,omitempty" json:"name,omitempty"`
+	Scopes []string `url:"scopes,omitempty" json:"scopes,omitempty"`
+	// ExpiresAt is a date formatted as "2006-01-02".
+	ExpiresAt *string `url:"expires_at,omitempty" json:"expires_at,omitempty"`
+}
+
+// CreatePersonalAccessToken creates a token for the authenticated user.
+// tgit doc: https://code.tencent.com/help/api/user#createPersonalAccessToken
+func (s *UsersService) CreatePersonalAccessToken(opts *CreatePersonalAccessTokenOptions) (*PersonalAccessToken, *Response, error) {
+	req, err := s.client.newRequest(http.MethodPost, newRoute("user/personal_access_tokens"), opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t := new(PersonalAccessToken)
+	resp, err := s.client.Do(req, t)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return t, resp, err
+}
+
+// RevokePersonalAccessToken https://code.tencent.com/help/api/user#revokePersonalAccessToken
+func (s *UsersService) RevokePersonalAccessToken(token int64) (*Response, error) {
+	u := newRoute("user/personal_access_tokens/%d", token)
+
+	req, err := s.client.newRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
+
+// RotatePersonalAccessToken creates a new token, switches the client over to
+// it and then revokes the old token. Services keep working with the new token
+// without being recreated. If the server returns no token value the client and
+// the old token are left untouched. If revoking fails the new token is still in
+// use and returned along with the error.
+func (s *UsersService) RotatePersonalAccessToken(old int64, opts *CreatePersonalAccessTokenOptions) (*PersonalAccessToken, *Response, error) {
+	t, resp, err := s.CreatePersonalAccessToken(opts)
+	if err != nil {
+		return nil, resp, err
+	}
+	// Without a value the client could not authenticate anymore, keep the
+	// old token.
+	if t.Token == "" {
+		return t, resp, errors.New("created personal access token has no value, keeping the current token")
+	}
+
+	s.client.SetToken(t.Token)
+
+	resp, err = s.RevokePersonalAccessToken(old)
+	if err != nil {
+		return t, resp, err
+	}
+
+	return t, resp, nil
+}
+
+type ListImpersonationTokensOptions struct {
+	ListOptions
+	State *string `url:"state,omitempty" json:"state,omitempty"`
+}
+
+// ListImpersonationTokens lists the impersonation tokens of a user, admin only.
+// tgit doc: https://code.tencent.com/help/api/user#listImpersonationTokens
+func (s *UsersService) ListImpersonationTokens(uid int64, opts *ListImpersonationTokensOptions) ([]*PersonalAccessToken, *Response, error) {
+	u := newRoute("users/%d/impersonation_tokens", uid)
+
+	req, err := s.client.newRequest(http.MethodGet, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var t []*PersonalAccessToken
+	resp, err := s.client.Do(req, &t)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return t, resp, err
+}
+
+type CreateImpersonationTokenOptions struct {
+	Name   *string  `url:"name,omitempty" json:"name,omitempty"`
+	Scopes []string `url:"scopes,omitempty" json:"scopes,omitempty"`
+	// ExpiresAt is a date formatted as "2006-01-02".
+	ExpiresAt *string `url:"expires_at,omitempty" json:"expires_at,omitempty"`
+}
+
+// CreateImpersonationToken creates a token acting as a user, admin only.
+// tgit doc: https://code.tencent.com/help/api/user#createImpersonationToken
+func (s *UsersService) CreateImpersonationToken(uid int64, opts *CreateImpersonationTokenOptions) (*PersonalAccessToken, *Response, error) {
+	u := newRoute("users/%d/impersonation_tokens", uid)
+
+	req, err := s.client.newRequest(http.MethodPost, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t := new(PersonalAccessToken)
+	resp, err := s.client.Do(req, t)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return t, resp, err
+}
+
+// RevokeImpersonationToken revokes an impersonation token, admin only.
+// tgit doc: https://code.tencent.com/help/api/user#revokeImpersonationToken
+func (s *UsersService) RevokeImpersonationToken(uid, token int64) (*Response, error) {
+	u := newRoute("users/%d/impersonation_tokens/%d", uid, token)
+
+	req, err := s.client.newRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
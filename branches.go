@@ -1,7 +1,6 @@
 package tgit
 
 import (
-	"fmt"
 	"net/http"
 	"net/url"
 )
@@ -31,9 +30,9 @@ func (s *BranchesService) ListBranches(pid interface{}, opts *ListBranchesOption
 	if err != nil {
 		return nil, nil, err
 	}
-	u := fmt.Sprintf("projects/%s/repository/branches", pathEscape(project))
+	u := newRoute("projects/%s/repository/branches", pathEscape(project))
 
-	req, err := s.client.NewRequest(http.MethodGet, u, opts)
+	req, err := s.client.newRequest(http.MethodGet, u, opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -52,9 +51,9 @@ func (s *BranchesService) GetBranch(pid interface{}, branch string) (*Branch, *R
 	if err != nil {
 		return nil, nil, err
 	}
-	u := fmt.Sprintf("projects/%s/repository/branches/%s", pathEscape(project), url.PathEscape(branch))
+	u := newRoute("projects/%s/repository/branches/%s", pathEscape(project), url.PathEscape(branch))
 
-	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	req, err := s.client.newRequest(http.MethodGet, u, nil)
 	if err != nil {
 		return nil, nil, err
 	}
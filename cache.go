@@ -0,0 +1,235 @@
+package tgit
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// CacheEntry is a cached GET response together with the validators used to
+// revalidate it.
+type CacheEntry struct {
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         []byte      `json:"body,omitempty"`
+}
+
+// Cache stores responses keyed by request URL. Implementations must be safe
+// for concurrent use.
+type Cache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+	Delete(key string)
+}
+
+// SetCache enables conditional requests for GET calls made through Client.Do,
+// pass nil to disable caching. Entries are keyed by URL, so a cache should not
+// be shared between clients authenticated as different users.
+func (c *Client) SetCache(cache Cache) {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+
+	c.cache = cache
+}
+
+func (c *Client) responseCache() Cache {
+	c.cacheMu.RLock()
+	defer c.cacheMu.RUnlock()
+
+	return c.cache
+}
+
+// setConditionalHeaders adds the validators of the entry cached for req, if
+// any, and returns that entry.
+func setConditionalHeaders(cache Cache, req *retryablehttp.Request) *CacheEntry {
+	entry, ok := cache.Get(req.URL.String())
+	if !ok {
+		return nil
+	}
+
+	if entry.ETag != "" {
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+	if entry.LastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.LastModified)
+	}
+	return entry
+}
+
+// applyCache serves the cached body on a 304 and stores cacheable 200
+// responses in cache. It reports whether resp carries a body that can be
+// decoded.
+func applyCache(cache Cache, req *retryablehttp.Request, resp *http.Response, entry *CacheEntry) (bool, error) {
+	key := req.URL.String()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		if entry == nil {
+			return false, nil
+		}
+		// A 304 only carries the headers that changed, fill in the rest
+		// (pagination, content type, ...) from the cached response.
+		for k, v := range entry.Header {
+			if _, ok := resp.Header[k]; !ok {
+				resp.Header[k] = v
+			}
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(entry.Body))
+		return true, nil
+
+	case http.StatusOK:
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag == "" && lastModified == "" {
+			cache.Delete(key)
+			return true, nil
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return false, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		cache.Set(key, &CacheEntry{
+			ETag:         etag,
+			LastModified: lastModified,
+			Header:       resp.Header.Clone(),
+			Body:         body,
+		})
+	}
+
+	return true, nil
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used entry
+// once it holds more than size entries.
+type MemoryCache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+func NewMemoryCache(size int) *MemoryCache {
+	if size < 1 {
+		size = 1
+	}
+	return &MemoryCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (m *MemoryCache) Get(key string) (*CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	m.ll.MoveToFront(e)
+	return e.Value.(*memoryCacheItem).entry, true
+}
+
+func (m *MemoryCache) Set(key string, entry *CacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e, ok := m.items[key]; ok {
+		e.Value.(*memoryCacheItem).entry = entry
+		m.ll.MoveToFront(e)
+		return
+	}
+
+	m.items[key] = m.ll.PushFront(&memoryCacheItem{key: key, entry: entry})
+	for m.ll.Len() > m.size {
+		oldest := m.ll.Back()
+		m.ll.Remove(oldest)
+		delete(m.items, oldest.Value.(*memoryCacheItem).key)
+	}
+}
+
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e, ok := m.items[key]; ok {
+		m.ll.Remove(e)
+		delete(m.items, key)
+	}
+}
+
+// DiskCache is a Cache that stores one JSON file per entry in a directory.
+type DiskCache struct {
+	mu  sync.Mutex
+	dir string
+}
+
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (d *DiskCache) Get(key string) (*CacheEntry, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	entry := new(CacheEntry)
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, false
+	}
+	return entry, true
+}
+
+func (d *DiskCache) Set(key string, entry *CacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Write to a temporary file first so readers never see a partial entry.
+	tmp := d.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return
+	}
+	os.Rename(tmp, d.path(key))
+}
+
+func (d *DiskCache) Delete(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	os.Remove(d.path(key))
+}
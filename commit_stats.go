@@ -0,0 +1,126 @@
+package tgit
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// ActivityStats counts commits and changed lines.
+type ActivityStats struct {
+	Commits   int
+	Additions int
+	Deletions int
+}
+
+func (a ActivityStats) String() string {
+	return Stringify(a)
+}
+
+func (a *ActivityStats) add(additions, deletions int) {
+	a.Additions += additions
+	a.Deletions += deletions
+}
+
+// CommitStats aggregates the activity of a range of commits.
+type CommitStats struct {
+	Total ActivityStats
+	// Authors is keyed by author email, or by name when the email is empty.
+	Authors map[string]*ActivityStats
+	// Days is keyed by the authored date, "2006-01-02" in the server
+	// location of the client.
+	Days map[string]*ActivityStats
+	// Paths is keyed by the new path of each changed file.
+	Paths map[string]*ActivityStats
+}
+
+func (s CommitStats) String() string {
+	return Stringify(s)
+}
+
+// CollectCommitStats walks every page of ListCommits with opts, typically
+// bounded by Since and Until, fetches the diff of each commit and aggregates
+// the changed lines per author, per day and per path. Merge commits are
+// skipped so their changes are not counted twice.
+func (s *CommitsService) CollectCommitStats(ctx context.Context, pid interface{}, opts *ListCommitsOptions) (*CommitStats, error) {
+	stats := &CommitStats{
+		Authors: make(map[string]*ActivityStats),
+		Days:    make(map[string]*ActivityStats),
+		Paths:   make(map[string]*ActivityStats),
+	}
+	loc := s.client.ServerLocation()
+
+	noMerges := func(c *Commit) bool { return len(c.ParentIDs) <= 1 }
+	err := s.walkCommits(ctx, pid, opts, noMerges, func(c *Commit, diffs []*Diff) bool {
+		stats.add(c, diffs, loc)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (s *CommitStats) add(c *Commit, diffs []*Diff, loc *time.Location) {
+	author := statsEntry(s.Authors, commitAuthor(c))
+	var day *ActivityStats
+	if c.AuthoredDate != nil {
+		day = statsEntry(s.Days, c.AuthoredDate.In(loc).Format("2006-01-02"))
+	}
+
+	s.Total.Commits++
+	author.Commits++
+	if day != nil {
+		day.Commits++
+	}
+	for _, d := range diffs {
+		additions, deletions := diffLines(d)
+		s.Total.add(additions, deletions)
+		author.add(additions, deletions)
+		if day != nil {
+			day.add(additions, deletions)
+		}
+
+		p := statsEntry(s.Paths, d.NewPath)
+		p.Commits++
+		p.add(additions, deletions)
+	}
+}
+
+func statsEntry(m map[string]*ActivityStats, key string) *ActivityStats {
+	a, ok := m[key]
+	if !ok {
+		a = new(ActivityStats)
+		m[key] = a
+	}
+	return a
+}
+
+func commitAuthor(c *Commit) string {
+	if c.AuthorEmail != "" {
+		return c.AuthorEmail
+	}
+	return c.AuthorName
+}
+
+// diffLines returns the line counts of d, counting them from the patch when
+// the server did not report any.
+func diffLines(d *Diff) (additions, deletions int) {
+	if d.Additions != 0 || d.Deletions != 0 || d.Diff == "" {
+		return d.Additions, d.Deletions
+	}
+	inHunk := false
+	for _, line := range strings.Split(d.Diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk:
+		case strings.HasPrefix(line, "+"):
+			additions++
+		case strings.HasPrefix(line, "-"):
+			deletions++
+		}
+	}
+	return additions, deletions
+}
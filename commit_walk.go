@@ -0,0 +1,95 @@
+package tgit
+
+import (
+	"context"
+	"sync"
+)
+
+// commitDiffConcurrency bounds the commit diffs fetched at once by
+// walkCommits.
+const commitDiffConcurrency = 4
+
+// walkCommits walks every page of ListCommits with opts and calls fn with each
+// commit accepted by include, nil accepting all, together with its diff,
+// newest first. Diffs are fetched concurrently in batches of
+// commitDiffConcurrency, fn is called in order from the calling goroutine and
+// stops the walk by returning false.
+func (s *CommitsService) walkCommits(ctx context.Context, pid interface{}, opts *ListCommitsOptions, include func(c *Commit) bool, fn func(c *Commit, diffs []*Diff) bool) error {
+	o := ListCommitsOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.PerPage == 0 {
+		o.PerPage = 100
+	}
+
+	for {
+		cs, resp, err := s.listCommits(ctx, pid, &o)
+		if err != nil {
+			return err
+		}
+
+		var page []*Commit
+		for _, c := range cs {
+			if include == nil || include(c) {
+				page = append(page, c)
+			}
+		}
+		for len(page) > 0 {
+			batch := page[:min(len(page), commitDiffConcurrency)]
+			page = page[len(batch):]
+
+			diffs, err := s.commitDiffs(ctx, pid, batch)
+			if err != nil {
+				return err
+			}
+			for i, c := range batch {
+				if !fn(c, diffs[i]) {
+					return nil
+				}
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return nil
+		}
+		o.Page = resp.NextPage
+	}
+}
+
+// commitDiffs fetches the diffs of cs concurrently, the first failure cancels
+// the other requests.
+func (s *CommitsService) commitDiffs(ctx context.Context, pid interface{}, cs []*Commit) ([][]*Diff, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		first error
+	)
+	diffs := make([][]*Diff, len(cs))
+	for i, c := range cs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d, _, err := s.getCommitDiff(ctx, pid, c.ID)
+			if err != nil {
+				mu.Lock()
+				if first == nil {
+					first = err
+					cancel()
+				}
+				mu.Unlock()
+				return
+			}
+			diffs[i] = d
+		}()
+	}
+	wg.Wait()
+
+	if first != nil {
+		return nil, first
+	}
+	return diffs, nil
+}
@@ -1,6 +1,7 @@
 package tgit
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -32,20 +33,24 @@ func (c Commit) String() string {
 
 type ListCommitsOptions struct {
 	ListOptions
-	RefName *string    `json:"ref_name,omitempty"`
+	RefName *string    `url:"ref_name,omitempty" json:"ref_name,omitempty"`
 	Since   *time.Time `url:"since,omitempty" json:"since,omitempty"`
 	Until   *time.Time `url:"until,omitempty" json:"until,omitempty"`
 	Path    *string    `url:"path,omitempty" json:"path,omitempty"`
 }
 
 func (s *CommitsService) ListCommits(pid interface{}, opts *ListCommitsOptions) ([]*Commit, *Response, error) {
+	return s.listCommits(context.Background(), pid, opts)
+}
+
+func (s *CommitsService) listCommits(ctx context.Context, pid interface{}, opts *ListCommitsOptions) ([]*Commit, *Response, error) {
 	project, err := parseID(pid)
 	if err != nil {
 		return nil, nil, err
 	}
-	u := fmt.Sprintf("projects/%s/repository/commits", pathEscape(project))
+	u := newRoute("projects/%s/repository/commits", pathEscape(project))
 
-	req, err := s.client.NewRequest(http.MethodGet, u, opts)
+	req, err := s.client.newRequestWithContext(ctx, http.MethodGet, u, opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -74,9 +79,9 @@ func (s *CommitsService) ListCommitRefs(pid interface{}, sha string, opts *GetCo
 	if err != nil {
 		return nil, nil, err
 	}
-	u := fmt.Sprintf("projects/%s/repository/commits/%s/refs", pathEscape(project), pathEscape(sha))
+	u := newRoute("projects/%s/repository/commits/%s/refs", pathEscape(project), pathEscape(sha))
 
-	req, err := s.client.NewRequest(http.MethodGet, u, opts)
+	req, err := s.client.newRequest(http.MethodGet, u, opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -98,9 +103,9 @@ func (s *CommitsService) GetCommit(pid interface{}, sha string) (*Commit, *Respo
 	if sha == "" {
 		return nil, nil, fmt.Errorf("SHA must be a non-empty string")
 	}
-	u := fmt.Sprintf("projects/%s/repository/commits/%s", pathEscape(project), url.PathEscape(sha))
+	u := newRoute("projects/%s/repository/commits/%s", pathEscape(project), url.PathEscape(sha))
 
-	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	req, err := s.client.newRequest(http.MethodGet, u, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -113,3 +118,80 @@ func (s *CommitsService) GetCommit(pid interface{}, sha string) (*Commit, *Respo
 
 	return c, resp, err
 }
+
+// GetCommitDiff https://code.tencent.com/help/api/commit#getCommitDiff
+func (s *CommitsService) GetCommitDiff(pid interface{}, sha string) ([]*Diff, *Response, error) {
+	return s.getCommitDiff(context.Background(), pid, sha)
+}
+
+func (s *CommitsService) getCommitDiff(ctx context.Context, pid interface{}, sha string) ([]*Diff, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sha == "" {
+		return nil, nil, fmt.Errorf("SHA must be a non-empty string")
+	}
+	u := newRoute("projects/%s/repository/commits/%s/diff", pathEscape(project), url.PathEscape(sha))
+
+	req, err := s.client.newRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ds []*Diff
+	resp, err := s.client.Do(req, &ds)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return ds, resp, err
+}
+
+type CommitStatus struct {
+	ID          int64  `json:"id"`
+	SHA         string `json:"sha"`
+	Ref         string `json:"ref"`
+	Status      string `json:"status"`
+	Context     string `json:"context"`
+	TargetURL   string `json:"target_url"`
+	Description string `json:"description"`
+	CreatedAt   *Time  `json:"created_at"`
+	UpdatedAt   *Time  `json:"updated_at"`
+	Author      *User  `json:"author"`
+}
+
+func (s CommitStatus) String() string {
+	return Stringify(s)
+}
+
+type ListCommitStatusesOptions struct {
+	ListOptions
+	Ref *string `url:"ref,omitempty" json:"ref,omitempty"`
+	All *bool   `url:"all,omitempty" json:"all,omitempty"`
+}
+
+// ListCommitStatuses https://code.tencent.com/help/api/commit#getCommitStatuses
+func (s *CommitsService) ListCommitStatuses(pid interface{}, sha string, opts *ListCommitStatusesOptions) ([]*CommitStatus, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sha == "" {
+		return nil, nil, fmt.Errorf("SHA must be a non-empty string")
+	}
+	u := newRoute("projects/%s/repository/commits/%s/statuses", pathEscape(project), url.PathEscape(sha))
+
+	req, err := s.client.newRequest(http.MethodGet, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cs []*CommitStatus
+	resp, err := s.client.Do(req, &cs)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cs, resp, err
+}
@@ -0,0 +1,209 @@
+package tgit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// Credentials authenticate a single request.
+type Credentials struct {
+	Token string
+
+	// OAuth sends Token as an OAUTH-TOKEN instead of a PRIVATE-TOKEN.
+	OAuth bool
+}
+
+// CredentialsProvider is consulted by Client.Do for every request, so the
+// credentials it returns may change while the client is in use.
+// Implementations must be safe for concurrent use.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// StaticCredentials always returns the same credentials.
+type StaticCredentials Credentials
+
+func (s StaticCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	return Credentials(s), nil
+}
+
+// CredentialsFunc adapts a function to a CredentialsProvider.
+type CredentialsFunc func(ctx context.Context) (Credentials, error)
+
+func (f CredentialsFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// EnvCredentials reads the token from the environment variable Name on every
+// request.
+type EnvCredentials struct {
+	Name  string
+	OAuth bool
+}
+
+func (e EnvCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	token := os.Getenv(e.Name)
+	if token == "" {
+		return Credentials{}, fmt.Errorf("environment variable %s is not set", e.Name)
+	}
+	return Credentials{Token: token, OAuth: e.OAuth}, nil
+}
+
+// FileCredentials reads the token from a file, such as a mounted secret, and
+// reloads it when the file changes. The file is checked at most once per
+// interval.
+type FileCredentials struct {
+	path     string
+	oauth    bool
+	interval time.Duration
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	checked time.Time
+}
+
+// NewFileCredentials loads the token from path, surrounding whitespace is
+// ignored.
+func NewFileCredentials(path string, oauth bool, interval time.Duration) (*FileCredentials, error) {
+	f := &FileCredentials{path: path, oauth: oauth, interval: interval}
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *FileCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if time.Since(f.checked) >= f.interval {
+		// Keep serving the last good token if the file is being replaced.
+		if err := f.reloadLocked(); err != nil && f.token == "" {
+			return Credentials{}, err
+		}
+	}
+	return Credentials{Token: f.token, OAuth: f.oauth}, nil
+}
+
+func (f *FileCredentials) reload() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.reloadLocked()
+}
+
+func (f *FileCredentials) reloadLocked() error {
+	f.checked = time.Now()
+
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+	if fi.ModTime().Equal(f.modTime) && f.token != "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	token := string(bytes.TrimSpace(data))
+	if token == "" {
+		return errors.New("credentials file " + f.path + " is empty")
+	}
+
+	f.token = token
+	f.modTime = fi.ModTime()
+	return nil
+}
+
+// refresher is implemented by providers that can replace credentials the
+// server rejected, see oauthSource and sessionCredentials.
+type refresher interface {
+	refresh(ctx context.Context, stale string) (Credentials, error)
+}
+
+// NewClientWithCredentials returns a client that authenticates every request
+// with the credentials returned by p at that time.
+func NewClientWithCredentials(hc *retryablehttp.Client, p CredentialsProvider) (*Client, error) {
+	client, err := newClient(hc)
+	if err != nil {
+		return nil, err
+	}
+
+	client.credentials = p
+	return client, nil
+}
+
+// SetCredentials replaces the provider used by the client. It is safe to call
+// while requests are in flight, they keep the credentials they started with.
+func (c *Client) SetCredentials(p CredentialsProvider) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	c.credentials = p
+}
+
+// SetToken switches the client to the private token. It is safe to call
+// while requests are in flight, they keep the token they started with.
+func (c *Client) SetToken(token string) {
+	c.SetCredentials(StaticCredentials{Token: token})
+}
+
+// credentialsProvider returns the current provider, logging basic auth
+// clients in on first use.
+func (c *Client) credentialsProvider(ctx context.Context) (CredentialsProvider, error) {
+	c.authMu.RLock()
+	p := c.credentials
+	c.authMu.RUnlock()
+	if p != nil {
+		return p, nil
+	}
+
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	// Another request may have logged in while waiting for the lock.
+	if c.credentials != nil {
+		return c.credentials, nil
+	}
+	if _, err := c.loginLocked(ctx); err != nil {
+		return nil, err
+	}
+	return c.credentials, nil
+}
+
+// setAuthHeaders authenticates req and returns the provider and token used.
+func (c *Client) setAuthHeaders(req *retryablehttp.Request) (CredentialsProvider, string, error) {
+	p, err := c.credentialsProvider(req.Context())
+	if err != nil {
+		return nil, "", err
+	}
+
+	creds, err := p.Credentials(req.Context())
+	if err != nil {
+		return nil, "", err
+	}
+	setTokenHeader(req, creds)
+	return p, creds.Token, nil
+}
+
+func setTokenHeader(req *retryablehttp.Request, creds Credentials) {
+	req.Header.Del("OAUTH-TOKEN")
+	req.Header.Del("PRIVATE-TOKEN")
+
+	if creds.OAuth {
+		req.Header.Set("OAUTH-TOKEN", creds.Token)
+		return
+	}
+	// https://code.tencent.com/help/api/prepare#authentication
+	req.Header.Set("PRIVATE-TOKEN", creds.Token)
+}
@@ -0,0 +1,169 @@
+package tgit
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type DeployKeysService struct {
+	client *Client
+}
+
+type DeployKey struct {
+	ID        int64  `json:"id"`
+	Title     string `json:"title"`
+	Key       string `json:"key"`
+	CanPush   bool   `json:"can_push"`
+	CreatedAt *Time  `json:"created_at"`
+}
+
+func (k DeployKey) String() string {
+	return Stringify(k)
+}
+
+type ListDeployKeysOptions struct {
+	ListOptions
+}
+
+// ListDeployKeys https://code.tencent.com/help/api/deploy_key#listDeployKeys
+func (s *DeployKeysService) ListDeployKeys(pid interface{}, opts *ListDeployKeysOptions) ([]*DeployKey, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/deploy_keys", pathEscape(project))
+
+	req, err := s.client.newRequest(http.MethodGet, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var k []*DeployKey
+	resp, err := s.client.Do(req, &k)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return k, resp, err
+}
+
+// GetDeployKey https://code.tencent.com/help/api/deploy_key#getDeployKey
+func (s *DeployKeysService) GetDeployKey(pid interface{}, key int64) (*DeployKey, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/deploy_keys/%d", pathEscape(project), key)
+
+	req, err := s.client.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k := new(DeployKey)
+	resp, err := s.client.Do(req, k)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return k, resp, err
+}
+
+type AddDeployKeyOptions struct {
+	Title   *string `url:"title,omitempty" json:"title,omitempty"`
+	Key     *string `url:"key,omitempty" json:"key,omitempty"`
+	CanPush *bool   `url:"can_push,omitempty" json:"can_push,omitempty"`
+}
+
+// AddDeployKey adds a deploy key to a project, the key is validated before it
+// is sent.
+// tgit doc: https://code.tencent.com/help/api/deploy_key#addDeployKey
+func (s *DeployKeysService) AddDeployKey(pid interface{}, opts *AddDeployKeyOptions) (*DeployKey, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts == nil || opts.Key == nil {
+		return nil, nil, fmt.Errorf("%w: key is required", ErrInvalidPublicKey)
+	}
+	if err := ValidatePublicKey(*opts.Key); err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/deploy_keys", pathEscape(project))
+
+	req, err := s.client.newRequest(http.MethodPost, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k := new(DeployKey)
+	resp, err := s.client.Do(req, k)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return k, resp, err
+}
+
+// EnableDeployKey enables a deploy key that already exists on another project.
+// tgit doc: https://code.tencent.com/help/api/deploy_key#enableDeployKey
+func (s *DeployKeysService) EnableDeployKey(pid interface{}, key int64) (*DeployKey, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/deploy_keys/%d/enable", pathEscape(project), key)
+
+	req, err := s.client.newRequest(http.MethodPost, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k := new(DeployKey)
+	resp, err := s.client.Do(req, k)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return k, resp, err
+}
+
+// DisableDeployKey removes a deploy key from the project, the key stays
+// available to the other projects it is enabled on.
+// tgit doc: https://code.tencent.com/help/api/deploy_key#disableDeployKey
+func (s *DeployKeysService) DisableDeployKey(pid interface{}, key int64) (*DeployKey, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/deploy_keys/%d/disable", pathEscape(project), key)
+
+	req, err := s.client.newRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k := new(DeployKey)
+	resp, err := s.client.Do(req, k)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return k, resp, err
+}
+
+// DeleteDeployKey https://code.tencent.com/help/api/deploy_key#deleteDeployKey
+func (s *DeployKeysService) DeleteDeployKey(pid interface{}, key int64) (*Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, err
+	}
+	u := newRoute("projects/%s/deploy_keys/%d", pathEscape(project), key)
+
+	req, err := s.client.newRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
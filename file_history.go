@@ -0,0 +1,72 @@
+package tgit
+
+import "context"
+
+// FileRevision is one commit in the history of a file.
+type FileRevision struct {
+	Commit *Commit
+	// Path is the path of the file as of Commit.
+	Path string
+	// Diff is the change of the file in Commit, nil when the diff did not
+	// list it.
+	Diff *Diff
+}
+
+func (r FileRevision) String() string {
+	return Stringify(r)
+}
+
+// FileHistory lists the commits that changed filePath, newest first, using
+// the Path option of ListCommits. When a commit renamed the file, the history
+// continues under the old path from the first parent of that commit. Only
+// Since, Until, RefName and PerPage of opts are used.
+func (s *CommitsService) FileHistory(ctx context.Context, pid interface{}, filePath string, opts *ListCommitsOptions) ([]*FileRevision, error) {
+	o := ListCommitsOptions{}
+	if opts != nil {
+		o = *opts
+	}
+
+	var history []*FileRevision
+	seen := make(map[string]bool)
+	unseen := func(c *Commit) bool { return !seen[c.ID] }
+	for filePath != "" {
+		o.Page = 0
+		path := filePath
+		o.Path = &path
+
+		next := ""
+		err := s.walkCommits(ctx, pid, &o, unseen, func(c *Commit, diffs []*Diff) bool {
+			seen[c.ID] = true
+			d := fileDiff(diffs, filePath)
+			history = append(history, &FileRevision{Commit: c, Path: filePath, Diff: d})
+
+			if d != nil && d.RenamedFile && d.OldPath != filePath && len(c.ParentIDs) > 0 {
+				next = d.OldPath
+				parent := c.ParentIDs[0]
+				o.RefName = &parent
+				return false
+			}
+			return d == nil || !d.NewFile
+		})
+		if err != nil {
+			return nil, err
+		}
+		filePath = next
+	}
+
+	return history, nil
+}
+
+func fileDiff(diffs []*Diff, filePath string) *Diff {
+	for _, d := range diffs {
+		if d.NewPath == filePath {
+			return d
+		}
+	}
+	for _, d := range diffs {
+		if d.DeletedFile && d.OldPath == filePath {
+			return d
+		}
+	}
+	return nil
+}
@@ -1,8 +1,9 @@
 module github.com/liwenqiu/go-tgit
 
-go 1.25
+go 1.26
 
 require (
+	github.com/go-json-experiment/json v0.0.0-20260820222146-c27c302e5fc3
 	github.com/google/go-querystring v1.1.0
 	github.com/hashicorp/go-retryablehttp v0.7.8
 )
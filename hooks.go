@@ -0,0 +1,169 @@
+package tgit
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// RequestHook observes the requests made through Client.Do. Hooks are called
+// synchronously, so they should not block.
+type RequestHook interface {
+	// BeforeRequest is called once before a request is sent.
+	BeforeRequest(req *http.Request)
+
+	// AfterResponse is called once after the last attempt, resp is nil when
+	// err is not.
+	AfterResponse(req *http.Request, resp *http.Response, err error, duration time.Duration)
+
+	// OnRetry is called before every attempt after the first one.
+	OnRetry(req *http.Request, attempt int)
+}
+
+// AddHook registers h on the client, it is safe to call while the client is
+// in use.
+func (c *Client) AddHook(h RequestHook) {
+	c.hooksMu.Lock()
+	defer c.hooksMu.Unlock()
+
+	// Copy on write, requests in flight keep the slice they started with.
+	hooks := make([]RequestHook, len(c.hooks), len(c.hooks)+1)
+	copy(hooks, c.hooks)
+	c.hooks = append(hooks, h)
+}
+
+type hooksContextKey struct{}
+
+// retryHook returns a RequestLogHook that calls next and reports retries to
+// the hooks carried by the request context. Going through the context keeps
+// this correct when several clients share the same retryablehttp.Client.
+func retryHook(next retryablehttp.RequestLogHook) retryablehttp.RequestLogHook {
+	return func(l retryablehttp.Logger, req *http.Request, attempt int) {
+		if next != nil {
+			next(l, req, attempt)
+		}
+		if attempt == 0 {
+			return
+		}
+		hooks, _ := req.Context().Value(hooksContextKey{}).([]RequestHook)
+		for _, h := range hooks {
+			h.OnRetry(req, attempt)
+		}
+	}
+}
+
+// installRetryHook chains retryHook onto the RequestLogHook of hc.
+func installRetryHook(hc *retryablehttp.Client) {
+	hc.RequestLogHook = retryHook(hc.RequestLogHook)
+}
+
+// do sends req through the HTTP client and reports it to the registered hooks.
+func (c *Client) do(req *retryablehttp.Request) (*http.Response, error) {
+	c.hooksMu.RLock()
+	hooks := c.hooks
+	c.hooksMu.RUnlock()
+
+	if len(hooks) == 0 {
+		return c.client.Do(req)
+	}
+
+	req = req.WithContext(context.WithValue(req.Context(), hooksContextKey{}, hooks))
+	for _, h := range hooks {
+		h.BeforeRequest(req.Request)
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	duration := time.Since(start)
+
+	for _, h := range hooks {
+		h.AfterResponse(req.Request, resp, err, duration)
+	}
+	return resp, err
+}
+
+type slogHook struct {
+	logger *slog.Logger
+}
+
+// NewSlogHook returns a RequestHook that logs every request, response and
+// retry to logger. Request headers are never logged.
+func NewSlogHook(logger *slog.Logger) RequestHook {
+	return &slogHook{logger: logger}
+}
+
+func (h *slogHook) BeforeRequest(req *http.Request) {
+	h.logger.Debug("tgit request", "method", req.Method, "url", req.URL.String())
+}
+
+func (h *slogHook) AfterResponse(req *http.Request, resp *http.Response, err error, duration time.Duration) {
+	if err != nil {
+		h.logger.Error("tgit request failed", "method", req.Method, "url", req.URL.String(), "duration", duration, "error", err)
+		return
+	}
+
+	level := slog.LevelInfo
+	if resp.StatusCode >= 400 {
+		level = slog.LevelWarn
+	}
+	h.logger.Log(req.Context(), level, "tgit response", "method", req.Method, "url", req.URL.String(), "status", resp.StatusCode, "duration", duration)
+}
+
+func (h *slogHook) OnRetry(req *http.Request, attempt int) {
+	h.logger.Warn("tgit retry", "method", req.Method, "url", req.URL.String(), "attempt", attempt)
+}
+
+// Metrics is the minimal set of instruments the client reports to, it can be
+// backed by Prometheus, StatsD or any other metrics library. Endpoints are
+// routes like "projects/:id/merge_request/:id/changes", see Endpoint.
+type Metrics interface {
+	// ObserveRequest records a finished request, status is 0 when the
+	// request failed without a response.
+	ObserveRequest(method, endpoint string, status int, duration time.Duration)
+
+	// IncRetry counts a retried request.
+	IncRetry(method, endpoint string)
+}
+
+type metricsHook struct {
+	metrics Metrics
+}
+
+// NewMetricsHook returns a RequestHook that reports to m.
+func NewMetricsHook(m Metrics) RequestHook {
+	return &metricsHook{metrics: m}
+}
+
+func (h *metricsHook) BeforeRequest(req *http.Request) {}
+
+func (h *metricsHook) AfterResponse(req *http.Request, resp *http.Response, err error, duration time.Duration) {
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	h.metrics.ObserveRequest(req.Method, Endpoint(req), status, duration)
+}
+
+func (h *metricsHook) OnRetry(req *http.Request, attempt int) {
+	h.metrics.IncRetry(req.Method, Endpoint(req))
+}
+
+// routeVerbs replaces the verbs of a route pattern, they only ever format
+// identifiers.
+var routeVerbs = strings.NewReplacer("%s", ":id", "%d", ":id")
+
+// Endpoint returns the API route req was built for, like
+// "projects/:id/merge_request/:id/changes", so it can be used as a metrics
+// label of bounded cardinality. Requests not built by the services of the
+// client, e.g. through Client.NewRequest, are reported as "other".
+func Endpoint(req *http.Request) string {
+	pattern, ok := req.Context().Value(routeContextKey{}).(string)
+	if !ok {
+		return "other"
+	}
+	return routeVerbs.Replace(pattern)
+}
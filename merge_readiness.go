@@ -0,0 +1,269 @@
+package tgit
+
+import (
+	"fmt"
+	"sort"
+)
+
+type BlockingReason string
+
+const (
+	BlockedNotOpen           BlockingReason = "not_open"
+	BlockedWorkInProgress    BlockingReason = "work_in_progress"
+	BlockedMergeStatus       BlockingReason = "merge_status"
+	BlockedChangesRequested  BlockingReason = "changes_requested"
+	BlockedNecessaryReviewer BlockingReason = "necessary_reviewer"
+	BlockedApprovals         BlockingReason = "approvals"
+	BlockedStatusPending     BlockingReason = "status_pending"
+	BlockedStatusFailed      BlockingReason = "status_failed"
+	BlockedProtectedBranch   BlockingReason = "protected_branch"
+)
+
+// BlockingCondition is one reason a merge request cannot be merged.
+type BlockingCondition struct {
+	Reason  BlockingReason
+	Message string
+}
+
+func (b BlockingCondition) String() string {
+	return Stringify(b)
+}
+
+// MergeReadiness reports whether a merge request can be merged, together with
+// the data the decision was based on.
+type MergeReadiness struct {
+	MergeRequest *MergeRequest
+	Project      *ProjectItem
+	TargetBranch *Branch
+	// Statuses holds the latest status of each context on the source commit.
+	Statuses []*CommitStatus
+	Blockers []*BlockingCondition
+}
+
+// Ready reports whether no blocking condition was found.
+func (r *MergeReadiness) Ready() bool {
+	return len(r.Blockers) == 0
+}
+
+func (r *MergeReadiness) block(reason BlockingReason, format string, a ...interface{}) {
+	r.Blockers = append(r.Blockers, &BlockingCondition{Reason: reason, Message: fmt.Sprintf(format, a...)})
+}
+
+// Review states of a MergeRequestViewer.
+const (
+	reviewApproved       = "approved"
+	reviewChangeRequired = "change_required"
+	reviewChangeDenied   = "change_denied"
+)
+
+// Commit status values.
+const (
+	statusSuccess = "success"
+	statusPending = "pending"
+	statusRunning = "running"
+)
+
+// EvaluateMergeReadiness fetches the merge request, its project, target branch
+// and the commit statuses of its source commit, and lists every condition
+// that blocks merging: state, work in progress, merge status, reviews against
+// the project approval rules, commit statuses and branch protection.
+func (s *MergeRequestsService) EvaluateMergeReadiness(pid interface{}, mergeRequest int64) (*MergeReadiness, error) {
+	mr, _, err := s.GetMergeRequest(pid, mergeRequest)
+	if err != nil {
+		return nil, err
+	}
+	project, _, err := s.client.Projects.GetProject(pid)
+	if err != nil {
+		return nil, err
+	}
+	branch, _, err := s.client.Branches.GetBranch(pid, mr.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []*CommitStatus
+	if mr.SourceCommit != "" {
+		opts := &ListCommitStatusesOptions{ListOptions: ListOptions{PerPage: 100}}
+		for {
+			cs, resp, err := s.client.Commits.ListCommitStatuses(pid, mr.SourceCommit, opts)
+			if err != nil {
+				return nil, err
+			}
+			statuses = append(statuses, cs...)
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+
+	r := &MergeReadiness{
+		MergeRequest: mr,
+		Project:      project,
+		TargetBranch: branch,
+		Statuses:     latestStatuses(statuses),
+	}
+	r.evaluate()
+	return r, nil
+}
+
+func (r *MergeReadiness) evaluate() {
+	mr := r.MergeRequest
+
+	if mr.State != "opened" && mr.State != "reopened" {
+		r.block(BlockedNotOpen, "merge request is %s", mr.State)
+	}
+	if mr.WorkInProgress {
+		r.block(BlockedWorkInProgress, "merge request is marked as work in progress")
+	}
+	if mr.MergeStatus != "" && mr.MergeStatus != "can_be_merged" {
+		r.block(BlockedMergeStatus, "merge status is %s", mr.MergeStatus)
+	}
+
+	r.evaluateReviews()
+
+	for _, st := range r.Statuses {
+		switch st.Status {
+		case statusSuccess:
+		case statusPending, statusRunning:
+			r.block(BlockedStatusPending, "status %q is %s", st.Context, st.Status)
+		default:
+			r.block(BlockedStatusFailed, "status %q is %s", st.Context, st.Status)
+		}
+	}
+
+	if r.TargetBranch != nil && r.TargetBranch.Protected && !r.TargetBranch.DevelopersCanMerge && !r.canMergeProtected() {
+		r.block(BlockedProtectedBranch, "target branch %s is protected and only masters can merge", r.TargetBranch.Name)
+	}
+}
+
+// masterAccessLevel is the access level of masters, who can merge into
+// protected branches; owners have a higher one.
+const masterAccessLevel = 40
+
+// canMergeProtected reports whether the project permissions of the current
+// user, directly or through a group, allow merging into protected branches.
+func (r *MergeReadiness) canMergeProtected() bool {
+	if r.Project == nil || r.Project.Permissions == nil {
+		return false
+	}
+	p := r.Project.Permissions
+	for _, access := range []Permission{p.ProjectAccess, p.GroupAccess, p.ShareGroupAccess} {
+		if access.AccessLevel >= masterAccessLevel {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateReviews applies the approval rules of the project.
+//
+// NecessaryApproverRule is the number of approvals required from necessary
+// reviewers; 0 or -1 means every necessary reviewer must approve.
+// ApproverRule is the number of approvals required from all reviewers; -1
+// means every reviewer must approve and 0 that no approval is required.
+// A rule asking for more approvals than there are reviewers cannot be met
+// and blocks the merge request. A user listed more than once counts once.
+func (r *MergeReadiness) evaluateReviews() {
+	mr := r.MergeRequest
+	necessary := uniqueViewers(mr.NecessaryReviewers, nil)
+	reviewers := append(append([]*MergeRequestViewer{}, necessary...), uniqueViewers(mr.SuggestionReviewers, necessary)...)
+
+	approvals := 0
+	for _, v := range reviewers {
+		switch v.ReviewState {
+		case reviewApproved:
+			approvals++
+		case reviewChangeRequired, reviewChangeDenied:
+			r.block(BlockedChangesRequested, "%s requested changes", v.Username)
+		}
+	}
+
+	var pending []string
+	necessaryApprovals := 0
+	for _, v := range necessary {
+		if v.ReviewState == reviewApproved {
+			necessaryApprovals++
+		} else {
+			pending = append(pending, v.Username)
+		}
+	}
+
+	necessaryRule, approverRule := 0, 0
+	if r.Project != nil {
+		necessaryRule, approverRule = r.Project.NecessaryApproverRule, r.Project.ApproverRule
+	}
+
+	required := necessaryRule
+	if required < 1 {
+		required = len(necessary)
+	}
+	if necessaryApprovals < required {
+		sort.Strings(pending)
+		r.block(BlockedNecessaryReviewer, "%d of %d necessary approvals, waiting for %v", necessaryApprovals, required, pending)
+	}
+
+	required = approverRule
+	if required < 0 {
+		required = len(reviewers)
+	}
+	if approvals < required {
+		r.block(BlockedApprovals, "%d of %d required approvals", approvals, required)
+	}
+}
+
+// uniqueViewers returns the viewers not already in seen, keeping the first
+// entry of each user. Users are told apart by ID, or by username when the ID
+// is missing.
+func uniqueViewers(viewers, seen []*MergeRequestViewer) []*MergeRequestViewer {
+	key := func(v *MergeRequestViewer) string {
+		if v.ID != 0 {
+			return fmt.Sprint(v.ID)
+		}
+		return "@" + v.Username
+	}
+	keys := make(map[string]bool)
+	for _, v := range seen {
+		keys[key(v)] = true
+	}
+
+	var result []*MergeRequestViewer
+	for _, v := range viewers {
+		if v == nil || keys[key(v)] {
+			continue
+		}
+		keys[key(v)] = true
+		result = append(result, v)
+	}
+	return result
+}
+
+// latestStatuses keeps the most recent status of each context.
+func latestStatuses(statuses []*CommitStatus) []*CommitStatus {
+	latest := make(map[string]*CommitStatus)
+	var order []string
+	for _, st := range statuses {
+		prev, ok := latest[st.Context]
+		if !ok {
+			order = append(order, st.Context)
+			latest[st.Context] = st
+			continue
+		}
+		if newerStatus(st, prev) {
+			latest[st.Context] = st
+		}
+	}
+
+	result := make([]*CommitStatus, 0, len(order))
+	for _, ctx := range order {
+		result = append(result, latest[ctx])
+	}
+	return result
+}
+
+func newerStatus(a, b *CommitStatus) bool {
+	if a.CreatedAt != nil && b.CreatedAt != nil && !a.CreatedAt.Equal(b.CreatedAt.Time) {
+		return a.CreatedAt.After(b.CreatedAt.Time)
+	}
+	return a.ID > b.ID
+}
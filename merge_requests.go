@@ -11,13 +11,22 @@ type MergeRequestsService struct {
 }
 
 type ListMergeRequestsOptions struct {
-	Id      string `json:"id,omitempty"`
-	Iid     int64  `url:"iid,omitempty" json:"iid,omitempty"`
-	State   string `url:"state,omitempty" json:"state,omitempty"`
-	OrderBy string `url:"order_by,omitempty" json:"order_by,omitempty"`
-	Sort    string `url:"sort,omitempty" json:"sort,omitempty"`
-	Page    int    `url:"page,omitempty" json:"page,omitempty"`
-	PerPage int    `url:"per_page,omitempty" json:"per_page,omitempty"`
+	ListOptions
+	Iid           int64      `url:"iid,omitempty" json:"iid,omitempty"`
+	State         string     `url:"state,omitempty" json:"state,omitempty"`
+	OrderBy       string     `url:"order_by,omitempty" json:"order_by,omitempty"`
+	Sort          string     `url:"sort,omitempty" json:"sort,omitempty"`
+	AuthorID      *int64     `url:"author_id,omitempty" json:"author_id,omitempty"`
+	AssigneeID    *int64     `url:"assignee_id,omitempty" json:"assignee_id,omitempty"`
+	ReviewerID    *int64     `url:"reviewer_id,omitempty" json:"reviewer_id,omitempty"`
+	Labels        []string   `url:"labels,comma,omitempty" json:"labels,omitempty"`
+	Milestone     *string    `url:"milestone,omitempty" json:"milestone,omitempty"`
+	SourceBranch  *string    `url:"source_branch,omitempty" json:"source_branch,omitempty"`
+	TargetBranch  *string    `url:"target_branch,omitempty" json:"target_branch,omitempty"`
+	CreatedAfter  *time.Time `url:"created_after,omitempty" json:"created_after,omitempty"`
+	CreatedBefore *time.Time `url:"created_before,omitempty" json:"created_before,omitempty"`
+	UpdatedAfter  *time.Time `url:"updated_after,omitempty" json:"updated_after,omitempty"`
+	UpdatedBefore *time.Time `url:"updated_before,omitempty" json:"updated_before,omitempty"`
 }
 
 type MergeRequestUser struct {
@@ -35,9 +44,9 @@ type Milestone struct {
 	Title       string `json:"title"`
 	State       string `json:"state"`
 	Iid         int64  `json:"iid"`
-	DueDate     string `json:"due_date"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
+	DueDate     *Time  `json:"due_date"`
+	CreatedAt   *Time  `json:"created_at"`
+	UpdatedAt   *Time  `json:"updated_at"`
 	Description string `json:"description"`
 }
 
@@ -45,8 +54,8 @@ type MergeRequestViewer struct {
 	Type           string `json:"type"`
 	ReviewState    string `json:"review_state"`
 	ReviewDuration int32  `json:"review_duration"`
-	CreatedAt      string `json:"created_at"`
-	UpdatedAt      string `json:"updated_at"`
+	CreatedAt      *Time  `json:"created_at"`
+	UpdatedAt      *Time  `json:"updated_at"`
 	ID             int64  `json:"id"`
 	Username       string `json:"username"`
 	WebURL         string `json:"web_url"`
@@ -67,9 +76,9 @@ type MergeRequest struct {
 	MergeStatus         string                `json:"merge_status"`
 	Iid                 int64                 `json:"iid"`
 	Description         string                `json:"description"`
-	CreatedAt           string                `json:"created_at"`
-	UpdatedAt           string                `json:"updated_at"`
-	ResolvedAt          *time.Time            `json:"resolved_at"`
+	CreatedAt           *Time                 `json:"created_at"`
+	UpdatedAt           *Time                 `json:"updated_at"`
+	ResolvedAt          *Time                 `json:"resolved_at"`
 	MergeType           string                `json:"merge_type"`
 	Assignee            *MergeRequestUser     `json:"assignee"`
 	Author              *MergeRequestUser     `json:"author"`
@@ -87,10 +96,14 @@ type MergeRequest struct {
 }
 
 // ListMergeRequests https://code.tencent.com/help/api/mergeRequest#getMergeRequests
-func (s *MergeRequestsService) ListMergeRequests(opts *ListMergeRequestsOptions) ([]*MergeRequest, *Response, error) {
-	url := fmt.Sprintf("projects/%s/merge_requests", opts.Id)
+func (s *MergeRequestsService) ListMergeRequests(pid interface{}, opts *ListMergeRequestsOptions) ([]*MergeRequest, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/merge_requests", pathEscape(project))
 
-	req, err := s.client.NewRequest(http.MethodGet, url, opts)
+	req, err := s.client.newRequest(http.MethodGet, u, opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -104,72 +117,270 @@ func (s *MergeRequestsService) ListMergeRequests(opts *ListMergeRequestsOptions)
 	return m, resp, nil
 }
 
-type ListMergeRequestChangeOptions struct {
-	Id             string
-	MergeRequestId int64
+// GetMergeRequest https://code.tencent.com/help/api/mergeRequest#getMergeRequest
+func (s *MergeRequestsService) GetMergeRequest(pid interface{}, mergeRequest int64) (*MergeRequest, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/merge_request/%d", pathEscape(project), mergeRequest)
+
+	req, err := s.client.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m := new(MergeRequest)
+	resp, err := s.client.Do(req, m)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return m, resp, err
 }
 
-type DiffFile struct {
-	OldPath     string `json:"old_path"`
-	NewPath     string `json:"new_path"`
-	AMode       int    `json:"a_mode"`
-	BMode       int    `json:"b_mode"`
-	Diff        string `json:"diff"`
-	NewFile     bool   `json:"new_file"`
-	RenamedFile bool   `json:"renamed_file"`
-	DeletedFile bool   `json:"deleted_file"`
-	IsTooLarge  bool   `json:"is_too_large"`
-	IsCollapse  bool   `json:"is_collapse"`
-	Additions   int    `json:"additions"`
-	Deletions   int    `json:"deletions"`
+type AcceptMergeRequestOptions struct {
+	MergeCommitMessage       *string `url:"merge_commit_message,omitempty" json:"merge_commit_message,omitempty"`
+	ShouldRemoveSourceBranch *bool   `url:"should_remove_source_branch,omitempty" json:"should_remove_source_branch,omitempty"`
+	// SHA must match the head of the source branch, otherwise the merge is
+	// rejected. It guards against merging commits that were not checked.
+	SHA *string `url:"sha,omitempty" json:"sha,omitempty"`
 }
 
+// AcceptMergeRequest https://code.tencent.com/help/api/mergeRequest#acceptMergeRequest
+func (s *MergeRequestsService) AcceptMergeRequest(pid interface{}, mergeRequest int64, opts *AcceptMergeRequestOptions) (*MergeRequest, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/merge_request/%d/merge", pathEscape(project), mergeRequest)
+
+	req, err := s.client.newRequest(http.MethodPut, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m := new(MergeRequest)
+	resp, err := s.client.Do(req, m)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return m, resp, err
+}
+
+type MergeRequestScopeValue string
+
+const (
+	CreatedByMe  MergeRequestScopeValue = "created_by_me"
+	AssignedToMe MergeRequestScopeValue = "assigned_to_me"
+	ReviewedByMe MergeRequestScopeValue = "reviewed_by_me"
+	InvolvingMe  MergeRequestScopeValue = "involving_me"
+	AllScope     MergeRequestScopeValue = "all"
+)
+
+type ListAllMergeRequestsOptions struct {
+	ListMergeRequestsOptions
+	Scope *MergeRequestScopeValue `url:"scope,omitempty" json:"scope,omitempty"`
+}
+
+// ListAllMergeRequests lists merge requests across all projects visible to
+// the authenticated user, narrowed by Scope, e.g. InvolvingMe.
+// tgit doc: https://code.tencent.com/help/api/mergeRequest#getAllMergeRequests
+func (s *MergeRequestsService) ListAllMergeRequests(opts *ListAllMergeRequestsOptions) ([]*MergeRequest, *Response, error) {
+	req, err := s.client.newRequest(http.MethodGet, newRoute("merge_requests"), opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var m []*MergeRequest
+	resp, err := s.client.Do(req, &m)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return m, resp, nil
+}
+
+// MergeRequestChange is a merge request together with the diffs of its files.
 type MergeRequestChange struct {
-	Labels              []string              `json:"labels"`
-	ID                  int64                 `json:"id"`
-	Title               string                `json:"title"`
-	TargetProjectID     int64                 `json:"target_project_id"`
-	TargetBranch        string                `json:"target_branch"`
-	SourceProjectID     int64                 `json:"source_project_id"`
-	SourceBranch        string                `json:"source_branch"`
-	State               string                `json:"state"`
-	MergeStatus         string                `json:"merge_status"`
-	Iid                 int64                 `json:"iid"`
-	Description         string                `json:"description"`
-	CreatedAt           string                `json:"created_at"`
-	UpdatedAt           string                `json:"updated_at"`
-	ResolvedAt          *time.Time            `json:"resolved_at"`
-	MergeType           string                `json:"merge_type"`
-	Assignee            *MergeRequestUser     `json:"assignee"`
-	Author              *MergeRequestUser     `json:"author"`
-	MergeCommitSha      string                `json:"merge_commit_sha"`
-	Milestone           *Milestone            `json:"milestone"`
-	NecessaryReviewers  []*MergeRequestViewer `json:"necessary_reviewers"`
-	SuggestionReviewers []*MergeRequestViewer `json:"suggestion_reviewers"`
-	BaseCommit          string                `json:"base_commit"`
-	TargetCommit        string                `json:"target_commit"`
-	SourceCommit        string                `json:"source_commit"`
-	ProjectID           int64                 `json:"project_id"`
-	WorkInProgress      bool                  `json:"work_in_progress"`
-	Upvotes             int                   `json:"upvotes"`
-	Downvotes           int                   `json:"downvotes"`
-	Files               []*DiffFile           `json:"files"`
+	MergeRequest
+	Files []*Diff `json:"files"`
 }
 
 // ListMergeRequestChange https://code.tencent.com/help/api/mergeRequest#searchMergeRequest
-func (s *MergeRequestsService) ListMergeRequestChange(opts *ListMergeRequestChangeOptions) (*MergeRequestChange, *Response, error) {
-	url := fmt.Sprintf("projects/:%s/merge_request/%d/changes", opts.Id, opts.MergeRequestId)
+func (s *MergeRequestsService) ListMergeRequestChange(pid interface{}, mergeRequest int64) (*MergeRequestChange, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/merge_request/%d/changes", pathEscape(project), mergeRequest)
 
-	req, err := s.client.NewRequest(http.MethodGet, url, nil)
+	req, err := s.client.newRequest(http.MethodGet, u, nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	var c *MergeRequestChange
+	c := new(MergeRequestChange)
+	resp, err := s.client.Do(req, c)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return c, resp, err
+}
+
+type ListMergeRequestCommitsOptions struct {
+	ListOptions
+}
+
+// ListMergeRequestCommits https://code.tencent.com/help/api/mergeRequest#getMergeRequestCommits
+func (s *MergeRequestsService) ListMergeRequestCommits(pid interface{}, mergeRequest int64, opts *ListMergeRequestCommitsOptions) ([]*Commit, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/merge_request/%d/commits", pathEscape(project), mergeRequest)
+
+	req, err := s.client.newRequest(http.MethodGet, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var c []*Commit
 	resp, err := s.client.Do(req, &c)
 	if err != nil {
 		return nil, resp, err
 	}
 
-	return c, resp, nil
+	return c, resp, err
+}
+
+// MergeRequestDiffVersion is the state of a merge request after one push to
+// its source branch.
+type MergeRequestDiffVersion struct {
+	ID             int64   `json:"id"`
+	MergeRequestID int64   `json:"merge_request_id"`
+	HeadCommitSHA  string  `json:"head_commit_sha"`
+	BaseCommitSHA  string  `json:"base_commit_sha"`
+	StartCommitSHA string  `json:"start_commit_sha"`
+	State          string  `json:"state"`
+	RealSize       string  `json:"real_size"`
+	CreatedAt      *Time   `json:"created_at"`
+	Diffs          []*Diff `json:"diffs"`
+}
+
+func (v MergeRequestDiffVersion) String() string {
+	return Stringify(v)
+}
+
+type ListMergeRequestDiffVersionsOptions struct {
+	ListOptions
+}
+
+// ListMergeRequestDiffVersions lists the diff versions of a merge request,
+// newest first.
+// tgit doc: https://code.tencent.com/help/api/mergeRequest#getMergeRequestVersions
+func (s *MergeRequestsService) ListMergeRequestDiffVersions(pid interface{}, mergeRequest int64, opts *ListMergeRequestDiffVersionsOptions) ([]*MergeRequestDiffVersion, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/merge_request/%d/versions", pathEscape(project), mergeRequest)
+
+	req, err := s.client.newRequest(http.MethodGet, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var v []*MergeRequestDiffVersion
+	resp, err := s.client.Do(req, &v)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return v, resp, err
+}
+
+// GetMergeRequestDiffVersion fetches a single diff version including its diffs.
+// tgit doc: https://code.tencent.com/help/api/mergeRequest#getMergeRequestVersion
+func (s *MergeRequestsService) GetMergeRequestDiffVersion(pid interface{}, mergeRequest, version int64) (*MergeRequestDiffVersion, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/merge_request/%d/versions/%d", pathEscape(project), mergeRequest, version)
+
+	req, err := s.client.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v := new(MergeRequestDiffVersion)
+	resp, err := s.client.Do(req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return v, resp, err
+}
+
+// CompareMergeRequestDiffVersions returns the changes pushed to a merge request
+// between two of its diff versions, e.g. since a reviewer last looked at it.
+func (s *MergeRequestsService) CompareMergeRequestDiffVersions(pid interface{}, mergeRequest, from, to int64) (*Compare, *Response, error) {
+	var fromSHA, toSHA string
+	opts := &ListMergeRequestDiffVersionsOptions{ListOptions: ListOptions{PerPage: 100}}
+	for {
+		versions, resp, err := s.ListMergeRequestDiffVersions(pid, mergeRequest, opts)
+		if err != nil {
+			return nil, resp, err
+		}
+		for _, v := range versions {
+			if v.ID == from {
+				fromSHA = v.HeadCommitSHA
+			}
+			if v.ID == to {
+				toSHA = v.HeadCommitSHA
+			}
+		}
+		if (fromSHA != "" && toSHA != "") || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	if fromSHA == "" || toSHA == "" {
+		return nil, nil, fmt.Errorf("diff versions %d and %d not found in merge request %d", from, to, mergeRequest)
+	}
+
+	return s.client.Repositories.Compare(pid, &CompareOptions{From: fromSHA, To: toSHA})
+}
+
+type GetMergeRequestFileDiffOptions struct {
+	FilePath *string `url:"file_path,omitempty" json:"file_path,omitempty"`
+	// VersionID selects a diff version, the latest when unset.
+	VersionID *int64 `url:"version_id,omitempty" json:"version_id,omitempty"`
+}
+
+// GetMergeRequestFileDiff fetches the full diff of a single file, for files
+// that ListMergeRequestChange reports with IsTooLarge or IsCollapse set.
+// tgit doc: https://code.tencent.com/help/api/mergeRequest#getMergeRequestFileDiff
+func (s *MergeRequestsService) GetMergeRequestFileDiff(pid interface{}, mergeRequest int64, opts *GetMergeRequestFileDiffOptions) (*Diff, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/merge_request/%d/diff_file", pathEscape(project), mergeRequest)
+
+	req, err := s.client.newRequest(http.MethodGet, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d := new(Diff)
+	resp, err := s.client.Do(req, d)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return d, resp, err
 }
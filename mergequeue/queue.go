@@ -0,0 +1,452 @@
+// Package mergequeue merges merge requests into their target branches one at
+// a time.
+//
+// Merges into the same target branch are serialized. Before merging, each
+// merge request is re-checked against the current head of its target branch
+// through a compare, the queue waits for its commit statuses to turn green
+// and for its source branch to contain the target head, and then accepts it
+// pinned to the checked source commit. Merges into different branches run
+// concurrently. Transient API failures are retried until the status timeout.
+package mergequeue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	tgit "github.com/liwenqiu/go-tgit"
+)
+
+type State string
+
+const (
+	StateQueued     State = "queued"
+	StateProcessing State = "processing"
+	StateMerged     State = "merged"
+	StateFailed     State = "failed"
+)
+
+var ErrAlreadyQueued = errors.New("mergequeue: merge request is already queued")
+
+// Item is a merge request waiting in the queue.
+type Item struct {
+	Project        string    `json:"project"`
+	MergeRequestID int64     `json:"merge_request_id"`
+	TargetBranch   string    `json:"target_branch"`
+	State          State     `json:"state"`
+	EnqueuedAt     time.Time `json:"enqueued_at"`
+
+	// Reason explains why an item failed.
+	Reason string `json:"reason,omitempty"`
+}
+
+func (it *Item) lane() string {
+	return it.Project + "\x00" + it.TargetBranch
+}
+
+func (it *Item) String() string {
+	return fmt.Sprintf("%s!%d -> %s (%s)", it.Project, it.MergeRequestID, it.TargetBranch, it.State)
+}
+
+type Options struct {
+	// PollInterval between readiness checks while statuses are pending,
+	// defaults to 30 seconds.
+	PollInterval time.Duration
+
+	// StatusTimeout fails an item that does not become mergeable in time,
+	// because of pending statuses, a stale source branch or API failures,
+	// defaults to one hour.
+	StatusTimeout time.Duration
+
+	// RequireUpToDate fails items whose source branch does not contain the
+	// head of the target branch at once, instead of waiting for a rebase.
+	RequireUpToDate bool
+
+	// RemoveSourceBranch removes the source branch after merging.
+	RemoveSourceBranch bool
+
+	// OnDone is called after an item was merged or failed.
+	OnDone func(it *Item)
+
+	// Logger reports store failures, defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// Queue is a merge queue backed by a Store. It is safe for concurrent use.
+type Queue struct {
+	client *tgit.Client
+	store  Store
+	opts   Options
+
+	mu     sync.Mutex
+	items  []*Item
+	active map[string]bool
+	wake   chan struct{}
+}
+
+// New returns a queue that restores its pending items from store. Items that
+// were being processed when the queue stopped are queued again.
+func New(ctx context.Context, client *tgit.Client, store Store, opts *Options) (*Queue, error) {
+	q := &Queue{
+		client: client,
+		store:  store,
+		active: make(map[string]bool),
+		wake:   make(chan struct{}, 1),
+	}
+	if opts != nil {
+		q.opts = *opts
+	}
+	if q.opts.PollInterval <= 0 {
+		q.opts.PollInterval = 30 * time.Second
+	}
+	if q.opts.StatusTimeout <= 0 {
+		q.opts.StatusTimeout = time.Hour
+	}
+	if q.opts.Logger == nil {
+		q.opts.Logger = slog.Default()
+	}
+
+	items, err := store.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, it := range items {
+		it.State = StateQueued
+	}
+	q.items = items
+
+	return q, nil
+}
+
+// Enqueue adds a merge request to the queue of its target branch.
+func (q *Queue) Enqueue(ctx context.Context, project string, mergeRequest int64) (*Item, error) {
+	mr, _, err := q.client.MergeRequests.GetMergeRequest(project, mergeRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, it := range q.items {
+		if it.Project == project && it.MergeRequestID == mergeRequest {
+			return nil, ErrAlreadyQueued
+		}
+	}
+
+	it := &Item{
+		Project:        project,
+		MergeRequestID: mergeRequest,
+		TargetBranch:   mr.TargetBranch,
+		State:          StateQueued,
+		EnqueuedAt:     time.Now(),
+	}
+	q.items = append(q.items, it)
+	if err := q.saveLocked(ctx); err != nil {
+		q.items = q.items[:len(q.items)-1]
+		return nil, err
+	}
+
+	q.notify()
+	c := *it
+	return &c, nil
+}
+
+// Remove drops a queued merge request, an item that is being processed
+// cannot be removed.
+func (q *Queue) Remove(ctx context.Context, project string, mergeRequest int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, it := range q.items {
+		if it.Project != project || it.MergeRequestID != mergeRequest {
+			continue
+		}
+		if it.State == StateProcessing {
+			return fmt.Errorf("mergequeue: %s is being processed", it)
+		}
+		q.items = append(q.items[:i], q.items[i+1:]...)
+		return q.saveLocked(ctx)
+	}
+	return nil
+}
+
+// Items returns a snapshot of the pending items in queue order.
+func (q *Queue) Items() []*Item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return cloneItems(q.items)
+}
+
+// Run processes the queue until ctx is done, with one worker per target
+// branch. It stops with the error of the store when saving the items fails.
+func (q *Queue) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		q.mu.Lock()
+		for _, it := range q.items {
+			lane := it.lane()
+			if q.active[lane] {
+				continue
+			}
+			q.active[lane] = true
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := q.runLane(ctx, lane); err != nil {
+					cancel(err)
+				}
+			}()
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-q.wake:
+		}
+	}
+}
+
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// runLane merges the items of one target branch in order until none is left,
+// it stops when the items cannot be saved.
+func (q *Queue) runLane(ctx context.Context, lane string) error {
+	defer func() {
+		q.mu.Lock()
+		delete(q.active, lane)
+		q.mu.Unlock()
+		// Items may have been added to the lane while it was shutting down.
+		q.notify()
+	}()
+
+	for ctx.Err() == nil {
+		it, err := q.next(ctx, lane)
+		if it == nil || err != nil {
+			return err
+		}
+
+		state, reason := q.process(ctx, it)
+		if ctx.Err() != nil {
+			// Interrupted, the item is queued again and retried.
+			return q.requeue(context.WithoutCancel(ctx), it)
+		}
+		if err := q.finish(ctx, it, state, reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// next marks the first item of lane as processing and returns a copy of it.
+// The item stays queued when that cannot be saved.
+func (q *Queue) next(ctx context.Context, lane string) (*Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, it := range q.items {
+		if it.lane() == lane {
+			it.State = StateProcessing
+			if err := q.saveLocked(ctx); err != nil {
+				it.State = StateQueued
+				return nil, err
+			}
+			c := *it
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+// requeue puts an interrupted item back in the queued state.
+func (q *Queue) requeue(ctx context.Context, it *Item) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, i := range q.items {
+		if i.Project == it.Project && i.MergeRequestID == it.MergeRequestID {
+			i.State = StateQueued
+			return q.saveLocked(ctx)
+		}
+	}
+	return nil
+}
+
+// finish removes a merged or failed item and reports it to OnDone, which is
+// called even if the removal cannot be saved since the outcome stands.
+func (q *Queue) finish(ctx context.Context, done *Item, state State, reason string) error {
+	q.mu.Lock()
+	for i, it := range q.items {
+		if it.Project == done.Project && it.MergeRequestID == done.MergeRequestID {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			break
+		}
+	}
+	err := q.saveLocked(ctx)
+	q.mu.Unlock()
+
+	done.State = state
+	done.Reason = reason
+	if q.opts.OnDone != nil {
+		q.opts.OnDone(done)
+	}
+	return err
+}
+
+// saveLocked persists the items. Failures are logged as well as returned.
+func (q *Queue) saveLocked(ctx context.Context) error {
+	err := q.store.Save(ctx, q.items)
+	if err != nil {
+		q.opts.Logger.ErrorContext(ctx, "mergequeue: saving items failed", "error", err)
+	}
+	return err
+}
+
+// process re-checks, waits for and merges a single item.
+func (q *Queue) process(ctx context.Context, it *Item) (State, string) {
+	deadline := time.Now().Add(q.opts.StatusTimeout)
+
+	for {
+		state, reason, wait := q.check(it)
+		if !wait {
+			return state, reason
+		}
+		if time.Now().After(deadline) {
+			return StateFailed, "timed out waiting for " + reason
+		}
+
+		t := time.NewTimer(q.opts.PollInterval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return StateQueued, ctx.Err().Error()
+		case <-t.C:
+		}
+	}
+}
+
+// check evaluates an item once and merges it when it is ready. It reports
+// wait when the item may still become mergeable: its statuses are pending,
+// its mergeability is still being checked, its source branch is behind the
+// target branch or the API failed transiently.
+func (q *Queue) check(it *Item) (state State, reason string, wait bool) {
+	r, err := q.client.MergeRequests.EvaluateMergeReadiness(it.Project, it.MergeRequestID)
+	if err != nil {
+		return StateFailed, err.Error(), transient(err)
+	}
+	mr := r.MergeRequest
+	if mr.State == "merged" {
+		// An earlier accept went through although its response was lost.
+		return StateMerged, "", false
+	}
+
+	if r.TargetBranch != nil && r.TargetBranch.Commit != nil {
+		behind, err := q.behind(it.Project, mr.SourceCommit, r.TargetBranch.Commit.ID)
+		if err != nil {
+			return StateFailed, err.Error(), transient(err)
+		}
+		if behind > 0 {
+			reason := fmt.Sprintf("source branch is %d commits behind %s", behind, mr.TargetBranch)
+			if q.opts.RequireUpToDate {
+				return StateFailed, reason + ", rebase required", false
+			}
+			return StateFailed, reason, true
+		}
+	}
+
+	if r.Ready() {
+		return q.accept(it, mr)
+	}
+	return StateFailed, describe(r.Blockers), waitable(r)
+}
+
+// behind returns the number of target branch commits missing from the
+// source commit.
+func (q *Queue) behind(project, source, targetHead string) (int, error) {
+	if source == "" || source == targetHead {
+		return 0, nil
+	}
+	cmp, _, err := q.client.Repositories.Compare(project, &tgit.CompareOptions{From: source, To: targetHead})
+	if err != nil {
+		return 0, err
+	}
+	if cmp.CommitsTotal > 0 {
+		return cmp.CommitsTotal, nil
+	}
+	return len(cmp.Commits), nil
+}
+
+func (q *Queue) accept(it *Item, mr *tgit.MergeRequest) (State, string, bool) {
+	opts := &tgit.AcceptMergeRequestOptions{
+		ShouldRemoveSourceBranch: &q.opts.RemoveSourceBranch,
+	}
+	if mr.SourceCommit != "" {
+		// Only merge what was checked, a new push sends the item back.
+		opts.SHA = &mr.SourceCommit
+	}
+
+	_, resp, err := q.client.MergeRequests.AcceptMergeRequest(it.Project, it.MergeRequestID, opts)
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotAcceptable || resp.StatusCode == http.StatusConflict) {
+			return StateFailed, "merge rejected: " + err.Error(), false
+		}
+		return StateFailed, err.Error(), transient(err)
+	}
+	return StateMerged, "", false
+}
+
+// transient reports whether err is worth retrying: a network failure, a
+// server error or rate limiting. Any other error, e.g. one validating the
+// request, fails the item.
+func transient(err error) bool {
+	var e *tgit.ErrorResponse
+	if errors.As(err, &e) {
+		return e.Response.StatusCode >= http.StatusInternalServerError || e.Response.StatusCode == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+	var urlErr *url.Error
+	return errors.As(err, &netErr) || errors.As(err, &urlErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// waitable reports whether every blocker of r may clear by itself: pending
+// statuses, or a merge status the server has not computed yet.
+func waitable(r *tgit.MergeReadiness) bool {
+	for _, b := range r.Blockers {
+		switch {
+		case b.Reason == tgit.BlockedStatusPending:
+		case b.Reason == tgit.BlockedMergeStatus && (r.MergeRequest.MergeStatus == "unchecked" || r.MergeRequest.MergeStatus == "checking"):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func describe(blockers []*tgit.BlockingCondition) string {
+	msgs := make([]string, len(blockers))
+	for i, b := range blockers {
+		msgs[i] = b.Message
+	}
+	return strings.Join(msgs, "; ")
+}
@@ -0,0 +1,100 @@
+package mergequeue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store persists the pending items of a queue so that it survives restarts.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	Load(ctx context.Context) ([]*Item, error)
+	Save(ctx context.Context, items []*Item) error
+}
+
+// MemoryStore keeps the queue in memory, it is mostly useful for tests.
+type MemoryStore struct {
+	mu    sync.Mutex
+	items []*Item
+}
+
+func NewMemoryStore() *MemoryStore {
+	return new(MemoryStore)
+}
+
+func (m *MemoryStore) Load(ctx context.Context) ([]*Item, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return cloneItems(m.items), nil
+}
+
+func (m *MemoryStore) Save(ctx context.Context, items []*Item) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.items = cloneItems(items)
+	return nil
+}
+
+// FileStore keeps the queue in a JSON file.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (f *FileStore) Load(ctx context.Context) ([]*Item, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*Item
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (f *FileStore) Save(ctx context.Context, items []*Item) error {
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+	// Write to a temporary file first so a crash never leaves a partial queue.
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
+
+func cloneItems(items []*Item) []*Item {
+	out := make([]*Item, len(items))
+	for i, it := range items {
+		c := *it
+		out[i] = &c
+	}
+	return out
+}
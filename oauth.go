@@ -0,0 +1,246 @@
+package tgit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// expiryDelta refreshes tokens slightly before they expire to absorb clock
+// skew and request latency.
+const expiryDelta = 30 * time.Second
+
+var ErrNoRefreshToken = errors.New("oauth: token expired and no refresh token is available")
+
+// OAuthConfig describes a TGit OAuth application.
+// tgit doc: https://code.tencent.com/help/api/oauth2
+type OAuthConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+
+	// BaseURL of the TGit instance, defaults to the public TGit.
+	BaseURL string
+
+	// HTTPClient used for token requests, defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+type OAuthToken struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresIn    int64     `json:"expires_in,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// Valid reports whether t holds an access token that is not about to expire.
+func (t *OAuthToken) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(expiryDelta).Before(t.Expiry)
+}
+
+// TokenStore loads and persists OAuth tokens, e.g. in a database or on disk.
+// Implementations must be safe for concurrent use.
+type TokenStore interface {
+	Token() (*OAuthToken, error)
+	SaveToken(t *OAuthToken) error
+}
+
+// MemoryTokenStore is a TokenStore that keeps the token in memory.
+type MemoryTokenStore struct {
+	mu    sync.Mutex
+	token *OAuthToken
+}
+
+func NewMemoryTokenStore(t *OAuthToken) *MemoryTokenStore {
+	return &MemoryTokenStore{token: t}
+}
+
+func (m *MemoryTokenStore) Token() (*OAuthToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.token, nil
+}
+
+func (m *MemoryTokenStore) SaveToken(t *OAuthToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.token = t
+	return nil
+}
+
+func (c *OAuthConfig) endpoint(path string) string {
+	base := c.BaseURL
+	if base == "" {
+		base = defaultBaseURL
+	}
+	return strings.TrimSuffix(base, "/") + "/oauth/" + path
+}
+
+// AuthCodeURL returns the URL of the consent page the user must be redirected
+// to, state is returned unchanged to the redirect URL.
+func (c *OAuthConfig) AuthCodeURL(state string) string {
+	v := url.Values{
+		"client_id":     {c.ClientID},
+		"redirect_uri":  {c.RedirectURL},
+		"response_type": {"code"},
+	}
+	if state != "" {
+		v.Set("state", state)
+	}
+	return c.endpoint("authorize") + "?" + v.Encode()
+}
+
+// Exchange trades an authorization code for a token.
+func (c *OAuthConfig) Exchange(ctx context.Context, code string) (*OAuthToken, error) {
+	return c.retrieveToken(ctx, url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {c.RedirectURL},
+	})
+}
+
+// Refresh trades a refresh token for a new token.
+func (c *OAuthConfig) Refresh(ctx context.Context, refreshToken string) (*OAuthToken, error) {
+	if refreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+	return c.retrieveToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+func (c *OAuthConfig) retrieveToken(ctx context.Context, v url.Values) (*OAuthToken, error) {
+	v.Set("client_id", c.ClientID)
+	v.Set("client_secret", c.ClientSecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint("token"), strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckResponse(resp); err != nil {
+		return nil, err
+	}
+
+	t := new(OAuthToken)
+	if err := json.NewDecoder(resp.Body).Decode(t); err != nil {
+		return nil, err
+	}
+	if t.AccessToken == "" {
+		return nil, fmt.Errorf("oauth: server response is missing access_token")
+	}
+	if t.ExpiresIn > 0 {
+		t.Expiry = time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+	return t, nil
+}
+
+// oauthSource hands out access tokens from a TokenStore and refreshes them
+// when they expire.
+type oauthSource struct {
+	config *OAuthConfig
+	store  TokenStore
+
+	mu sync.Mutex
+}
+
+// Credentials returns a valid access token, refreshing the stored one if
+// needed.
+func (s *oauthSource) Credentials(ctx context.Context) (Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, err := s.store.Token()
+	if err != nil {
+		return Credentials{}, err
+	}
+	if t.Valid() {
+		return Credentials{Token: t.AccessToken, OAuth: true}, nil
+	}
+	if t == nil {
+		return Credentials{}, ErrNoRefreshToken
+	}
+
+	token, err := s.refreshLocked(ctx, t)
+	if err != nil {
+		return Credentials{}, err
+	}
+	return Credentials{Token: token, OAuth: true}, nil
+}
+
+// refresh forces a refresh after the server rejected stale. When another
+// request refreshed in the meantime the newer token is returned instead.
+func (s *oauthSource) refresh(ctx context.Context, stale string) (Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, err := s.store.Token()
+	if err != nil {
+		return Credentials{}, err
+	}
+	if t == nil {
+		return Credentials{}, ErrNoRefreshToken
+	}
+	if t.AccessToken != stale && t.Valid() {
+		return Credentials{Token: t.AccessToken, OAuth: true}, nil
+	}
+	token, err := s.refreshLocked(ctx, t)
+	if err != nil {
+		return Credentials{}, err
+	}
+	return Credentials{Token: token, OAuth: true}, nil
+}
+
+func (s *oauthSource) refreshLocked(ctx context.Context, t *OAuthToken) (string, error) {
+	nt, err := s.config.Refresh(ctx, t.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+	// Servers may omit the refresh token when it does not rotate.
+	if nt.RefreshToken == "" {
+		nt.RefreshToken = t.RefreshToken
+	}
+	if err := s.store.SaveToken(nt); err != nil {
+		return "", err
+	}
+	return nt.AccessToken, nil
+}
+
+// NewOAuthFlowClient returns a client that reads its token from store and
+// refreshes it through config when it expires or the server answers 401.
+func NewOAuthFlowClient(hc *retryablehttp.Client, config *OAuthConfig, store TokenStore) (*Client, error) {
+	client, err := newClient(hc)
+	if err != nil {
+		return nil, err
+	}
+
+	client.credentials = &oauthSource{config: config, store: store}
+	return client, nil
+}
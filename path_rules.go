@@ -0,0 +1,290 @@
+package tgit
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PathRule assigns users to the files matching Pattern. Count is the number of
+// approvals required from Users, 0 meaning all of them.
+//
+// Patterns follow gitignore conventions: "*" and "?" match within a path
+// segment, "**" matches any number of segments, a leading "/" anchors the
+// pattern at the repository root, a trailing "/" matches everything below a
+// directory and a pattern without "/" matches a file name at any depth.
+//
+// The pattern is compiled on first use, it must not be changed afterwards.
+type PathRule struct {
+	Pattern string
+	Users   []string
+	Count   int
+
+	// Comments holds the comment and blank lines above the rule, so that
+	// serializing parsed rules keeps them.
+	Comments []string
+
+	// trailer holds the comment and blank lines after the last rule.
+	trailer []string
+
+	once sync.Once
+	re   *regexp.Regexp
+	err  error
+}
+
+// PathRules is the parsed form of ProjectItem.PathReviewerRules and
+// ProjectItem.FileOwnerPathRules, the settings of EditProject, see
+// https://code.tencent.com/help/api/project#editProject. Each non-empty line
+// of the text form holds one rule:
+//
+//	<pattern> [count] <user>[,<user>...]
+//
+// Lines starting with "#" are comments. They are kept with the rule below
+// them, or with the last rule when none follows; comments in a text without
+// any rule are dropped.
+type PathRules []*PathRule
+
+// ParsePathRules parses the text form of path rules.
+func ParsePathRules(s string) (PathRules, error) {
+	var (
+		rules    PathRules
+		comments []string
+	)
+
+	sc := bufio.NewScanner(strings.NewReader(s))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			comments = append(comments, line)
+			continue
+		}
+
+		fields := strings.Fields(line)
+		rule := &PathRule{Pattern: fields[0], Comments: comments}
+		comments = nil
+		switch len(fields) {
+		case 2:
+			rule.Users = splitUsers(fields[1])
+		case 3:
+			count, err := strconv.Atoi(fields[1])
+			if err != nil || count < 0 {
+				return nil, fmt.Errorf("line %d: invalid approval count %q", n, fields[1])
+			}
+			rule.Count = count
+			rule.Users = splitUsers(fields[2])
+		default:
+			return nil, fmt.Errorf("line %d: expected \"<pattern> [count] <users>\", got %q", n, line)
+		}
+		if len(rule.Users) == 0 {
+			return nil, fmt.Errorf("line %d: no users given", n)
+		}
+		if _, err := rule.regexp(); err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	// Keep trailing comments, but not the blank lines ending the text.
+	for len(comments) > 0 && comments[len(comments)-1] == "" {
+		comments = comments[:len(comments)-1]
+	}
+	if len(rules) > 0 {
+		rules[len(rules)-1].trailer = comments
+	}
+	return rules, nil
+}
+
+func splitUsers(s string) []string {
+	var users []string
+	for _, u := range strings.Split(s, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			users = append(users, u)
+		}
+	}
+	return users
+}
+
+// String serializes the rules into the text form accepted by ParsePathRules,
+// suitable for EditProjectOptions.PathReviewerRules and FileOwnerPathRules.
+func (r PathRules) String() string {
+	var b strings.Builder
+	for _, rule := range r {
+		for _, c := range rule.Comments {
+			b.WriteString(c)
+			b.WriteByte('\n')
+		}
+		b.WriteString(rule.Pattern)
+		if rule.Count > 0 {
+			fmt.Fprintf(&b, " %d", rule.Count)
+		}
+		b.WriteByte(' ')
+		b.WriteString(strings.Join(rule.Users, ","))
+		b.WriteByte('\n')
+		for _, c := range rule.trailer {
+			b.WriteString(c)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// regexp compiles the pattern once, it is safe for concurrent use.
+func (p *PathRule) regexp() (*regexp.Regexp, error) {
+	p.once.Do(func() {
+		p.re, p.err = compilePathPattern(p.Pattern)
+	})
+	return p.re, p.err
+}
+
+func compilePathPattern(pattern string) (*regexp.Regexp, error) {
+	original := pattern
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	dir := strings.HasSuffix(pattern, "/")
+	pattern = strings.Trim(pattern, "/")
+	if pattern == "" {
+		pattern = "**"
+	}
+
+	var b strings.Builder
+	if !anchored {
+		b.WriteString("^(?:.*/)?")
+	} else {
+		b.WriteString("^")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// "**/" matches zero or more directories.
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if dir {
+		b.WriteString("/.*$")
+	} else {
+		// A pattern naming a directory also covers everything below it.
+		b.WriteString("(?:/.*)?$")
+	}
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", original, err)
+	}
+	return re, nil
+}
+
+// Matches reports whether path, relative to the repository root, is covered
+// by the rule.
+func (p *PathRule) Matches(path string) bool {
+	re, err := p.regexp()
+	if err != nil {
+		return false
+	}
+	return re.MatchString(strings.TrimPrefix(path, "/"))
+}
+
+// PathRuleMatch is a rule together with the changed paths it covers.
+type PathRuleMatch struct {
+	Rule  *PathRule
+	Paths []string
+}
+
+// Required returns every rule covering at least one of paths, in rule order.
+// This is how path reviewer rules apply: each matching rule adds its
+// reviewers.
+func (r PathRules) Required(paths []string) PathRuleMatches {
+	var matches PathRuleMatches
+	for _, rule := range r {
+		m := &PathRuleMatch{Rule: rule}
+		for _, p := range paths {
+			if rule.Matches(p) {
+				m.Paths = append(m.Paths, p)
+			}
+		}
+		if len(m.Paths) > 0 {
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// Owners returns, for each of paths, the users of the last matching rule.
+// This is how file owner rules apply: later rules override earlier ones.
+// Paths without owner are left out.
+func (r PathRules) Owners(paths []string) map[string][]string {
+	owners := make(map[string][]string)
+	for _, p := range paths {
+		for i := len(r) - 1; i >= 0; i-- {
+			if r[i].Matches(p) {
+				owners[p] = r[i].Users
+				break
+			}
+		}
+	}
+	return owners
+}
+
+// PathRuleMatches is the result of PathRules.Required.
+type PathRuleMatches []*PathRuleMatch
+
+// Users returns the sorted, de-duplicated users of all matched rules.
+func (m PathRuleMatches) Users() []string {
+	seen := make(map[string]bool)
+	var users []string
+	for _, match := range m {
+		for _, u := range match.Rule.Users {
+			if !seen[u] {
+				seen[u] = true
+				users = append(users, u)
+			}
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+// ChangedPaths returns the paths touched by diffs, as found in a Compare or a
+// MergeRequestChange. Renamed files contribute both paths.
+func ChangedPaths(diffs []*Diff) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, d := range diffs {
+		for _, p := range []string{d.OldPath, d.NewPath} {
+			if p != "" && !seen[p] {
+				seen[p] = true
+				paths = append(paths, p)
+			}
+		}
+	}
+	return paths
+}
+
+// ParsePathReviewerRules parses the path reviewer rules of the project.
+func (p *ProjectItem) ParsePathReviewerRules() (PathRules, error) {
+	return ParsePathRules(p.PathReviewerRules)
+}
+
+// ParseFileOwnerPathRules parses the file owner rules of the project.
+func (p *ProjectItem) ParseFileOwnerPathRules() (PathRules, error) {
+	return ParsePathRules(p.FileOwnerPathRules)
+}
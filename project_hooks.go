@@ -0,0 +1,156 @@
+package tgit
+
+import (
+	"net/http"
+)
+
+type ProjectHooksService struct {
+	client *Client
+}
+
+type ProjectHook struct {
+	ID                  int64  `json:"id"`
+	URL                 string `json:"url"`
+	ProjectID           int64  `json:"project_id"`
+	PushEvents          bool   `json:"push_events"`
+	TagPushEvents       bool   `json:"tag_push_events"`
+	MergeRequestsEvents bool   `json:"merge_requests_events"`
+	IssuesEvents        bool   `json:"issues_events"`
+	NoteEvents          bool   `json:"note_events"`
+	ReviewEvents        bool   `json:"review_events"`
+	CreatedAt           *Time  `json:"created_at"`
+}
+
+func (h ProjectHook) String() string {
+	return Stringify(h)
+}
+
+type ListProjectHooksOptions struct {
+	ListOptions
+}
+
+// ListProjectHooks https://code.tencent.com/help/api/project#listProjectHooks
+func (s *ProjectHooksService) ListProjectHooks(pid interface{}, opts *ListProjectHooksOptions) ([]*ProjectHook, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/hooks", pathEscape(project))
+
+	req, err := s.client.newRequest(http.MethodGet, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var h []*ProjectHook
+	resp, err := s.client.Do(req, &h)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return h, resp, err
+}
+
+// GetProjectHook https://code.tencent.com/help/api/project#getProjectHook
+func (s *ProjectHooksService) GetProjectHook(pid interface{}, hook int64) (*ProjectHook, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/hooks/%d", pathEscape(project), hook)
+
+	req, err := s.client.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h := new(ProjectHook)
+	resp, err := s.client.Do(req, h)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return h, resp, err
+}
+
+type AddProjectHookOptions struct {
+	URL                 *string `url:"url,omitempty" json:"url,omitempty"`
+	PushEvents          *bool   `url:"push_events,omitempty" json:"push_events,omitempty"`
+	TagPushEvents       *bool   `url:"tag_push_events,omitempty" json:"tag_push_events,omitempty"`
+	MergeRequestsEvents *bool   `url:"merge_requests_events,omitempty" json:"merge_requests_events,omitempty"`
+	IssuesEvents        *bool   `url:"issues_events,omitempty" json:"issues_events,omitempty"`
+	NoteEvents          *bool   `url:"note_events,omitempty" json:"note_events,omitempty"`
+	ReviewEvents        *bool   `url:"review_events,omitempty" json:"review_events,omitempty"`
+	Token               *string `url:"token,omitempty" json:"token,omitempty"`
+}
+
+// AddProjectHook https://code.tencent.com/help/api/project#addProjectHook
+func (s *ProjectHooksService) AddProjectHook(pid interface{}, opts *AddProjectHookOptions) (*ProjectHook, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/hooks", pathEscape(project))
+
+	req, err := s.client.newRequest(http.MethodPost, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h := new(ProjectHook)
+	resp, err := s.client.Do(req, h)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return h, resp, err
+}
+
+type EditProjectHookOptions struct {
+	URL                 *string `url:"url,omitempty" json:"url,omitempty"`
+	PushEvents          *bool   `url:"push_events,omitempty" json:"push_events,omitempty"`
+	TagPushEvents       *bool   `url:"tag_push_events,omitempty" json:"tag_push_events,omitempty"`
+	MergeRequestsEvents *bool   `url:"merge_requests_events,omitempty" json:"merge_requests_events,omitempty"`
+	IssuesEvents        *bool   `url:"issues_events,omitempty" json:"issues_events,omitempty"`
+	NoteEvents          *bool   `url:"note_events,omitempty" json:"note_events,omitempty"`
+	ReviewEvents        *bool   `url:"review_events,omitempty" json:"review_events,omitempty"`
+	Token               *string `url:"token,omitempty" json:"token,omitempty"`
+}
+
+// EditProjectHook https://code.tencent.com/help/api/project#editProjectHook
+func (s *ProjectHooksService) EditProjectHook(pid interface{}, hook int64, opts *EditProjectHookOptions) (*ProjectHook, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/hooks/%d", pathEscape(project), hook)
+
+	req, err := s.client.newRequest(http.MethodPut, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h := new(ProjectHook)
+	resp, err := s.client.Do(req, h)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return h, resp, err
+}
+
+// DeleteProjectHook https://code.tencent.com/help/api/project#deleteProjectHook
+func (s *ProjectHooksService) DeleteProjectHook(pid interface{}, hook int64) (*Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, err
+	}
+	u := newRoute("projects/%s/hooks/%d", pathEscape(project), hook)
+
+	req, err := s.client.newRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
@@ -131,8 +131,8 @@ type ProjectItem struct {
 
 // ListProjects https://code.tencent.com/help/api/project#searchProjectByName
 func (s *ProjectsService) ListProjects(opts *ListProjectsOptions) ([]*ProjectItem, *Response, error) {
-	url := "projects"
-	req, err := s.client.NewRequest(http.MethodGet, url, opts)
+	url := newRoute("projects")
+	req, err := s.client.newRequest(http.MethodGet, url, opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -145,3 +145,211 @@ func (s *ProjectsService) ListProjects(opts *ListProjectsOptions) ([]*ProjectIte
 
 	return p, resp, nil
 }
+
+// GetProject https://code.tencent.com/help/api/project#getProject
+func (s *ProjectsService) GetProject(pid interface{}) (*ProjectItem, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s", pathEscape(project))
+
+	req, err := s.client.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := new(ProjectItem)
+	resp, err := s.client.Do(req, p)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return p, resp, err
+}
+
+type CreateProjectOptions struct {
+	Name                 *string               `url:"name,omitempty" json:"name,omitempty"`
+	Path                 *string               `url:"path,omitempty" json:"path,omitempty"`
+	NamespaceID          *int64                `url:"namespace_id,omitempty" json:"namespace_id,omitempty"`
+	Description          *string               `url:"description,omitempty" json:"description,omitempty"`
+	IssuesEnabled        *bool                 `url:"issues_enabled,omitempty" json:"issues_enabled,omitempty"`
+	MergeRequestsEnabled *bool                 `url:"merge_requests_enabled,omitempty" json:"merge_requests_enabled,omitempty"`
+	WikiEnabled          *bool                 `url:"wiki_enabled,omitempty" json:"wiki_enabled,omitempty"`
+	SnippetsEnabled      *bool                 `url:"snippets_enabled,omitempty" json:"snippets_enabled,omitempty"`
+	ReviewEnabled        *bool                 `url:"review_enabled,omitempty" json:"review_enabled,omitempty"`
+	VisibilityLevel      *VisibilityLevelValue `url:"visibility_level,omitempty" json:"visibility_level,omitempty"`
+	ImportURL            *string               `url:"import_url,omitempty" json:"import_url,omitempty"`
+}
+
+// CreateProject https://code.tencent.com/help/api/project#createProject
+func (s *ProjectsService) CreateProject(opts *CreateProjectOptions) (*ProjectItem, *Response, error) {
+	req, err := s.client.newRequest(http.MethodPost, newRoute("projects"), opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := new(ProjectItem)
+	resp, err := s.client.Do(req, p)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return p, resp, err
+}
+
+type EditProjectOptions struct {
+	Name                      *string               `url:"name,omitempty" json:"name,omitempty"`
+	Path                      *string               `url:"path,omitempty" json:"path,omitempty"`
+	Description               *string               `url:"description,omitempty" json:"description,omitempty"`
+	DefaultBranch             *string               `url:"default_branch,omitempty" json:"default_branch,omitempty"`
+	IssuesEnabled             *bool                 `url:"issues_enabled,omitempty" json:"issues_enabled,omitempty"`
+	MergeRequestsEnabled      *bool                 `url:"merge_requests_enabled,omitempty" json:"merge_requests_enabled,omitempty"`
+	WikiEnabled               *bool                 `url:"wiki_enabled,omitempty" json:"wiki_enabled,omitempty"`
+	SnippetsEnabled           *bool                 `url:"snippets_enabled,omitempty" json:"snippets_enabled,omitempty"`
+	ReviewEnabled             *bool                 `url:"review_enabled,omitempty" json:"review_enabled,omitempty"`
+	ForkEnabled               *bool                 `url:"fork_enabled,omitempty" json:"fork_enabled,omitempty"`
+	VisibilityLevel           *VisibilityLevelValue `url:"visibility_level,omitempty" json:"visibility_level,omitempty"`
+	TagNameRegex              *string               `url:"tag_name_regex,omitempty" json:"tag_name_regex,omitempty"`
+	TagCreatePushLevel        *int                  `url:"tag_create_push_level,omitempty" json:"tag_create_push_level,omitempty"`
+	BranchNameRegex           *string               `url:"branch_name_regex,omitempty" json:"branch_name_regex,omitempty"`
+	SuggestionReviewers       *string               `url:"suggestion_reviewers,omitempty" json:"suggestion_reviewers,omitempty"`
+	NecessaryReviewers        *string               `url:"necessary_reviewers,omitempty" json:"necessary_reviewers,omitempty"`
+	PathReviewerRules         *string               `url:"path_reviewer_rules,omitempty" json:"path_reviewer_rules,omitempty"`
+	ApproverRule              *int                  `url:"approver_rule,omitempty" json:"approver_rule,omitempty"`
+	NecessaryApproverRule     *int                  `url:"necessary_approver_rule,omitempty" json:"necessary_approver_rule,omitempty"`
+	CanApproveByCreator       *bool                 `url:"can_approve_by_creator,omitempty" json:"can_approve_by_creator,omitempty"`
+	AutoCreateReviewAfterPush *bool                 `url:"auto_create_review_after_push,omitempty" json:"auto_create_review_after_push,omitempty"`
+	ForbiddenModifyRule       *bool                 `url:"forbidden_modify_rule,omitempty" json:"forbidden_modify_rule,omitempty"`
+	PushResetEnabled          *bool                 `url:"push_reset_enabled,omitempty" json:"push_reset_enabled,omitempty"`
+	MergeRequestTemplate      *string               `url:"merge_request_template,omitempty" json:"merge_request_template,omitempty"`
+	FileOwnerPathRules        *string               `url:"file_owner_path_rules,omitempty" json:"file_owner_path_rules,omitempty"`
+}
+
+// EditProject https://code.tencent.com/help/api/project#editProject
+func (s *ProjectsService) EditProject(pid interface{}, opts *EditProjectOptions) (*ProjectItem, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s", pathEscape(project))
+
+	req, err := s.client.newRequest(http.MethodPut, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := new(ProjectItem)
+	resp, err := s.client.Do(req, p)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return p, resp, err
+}
+
+type ForkProjectOptions struct {
+	NamespaceID *int64 `url:"namespace_id,omitempty" json:"namespace_id,omitempty"`
+}
+
+// ForkProject https://code.tencent.com/help/api/project#forkProject
+func (s *ProjectsService) ForkProject(pid interface{}, opts *ForkProjectOptions) (*ProjectItem, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/fork/%s", pathEscape(project))
+
+	req, err := s.client.newRequest(http.MethodPost, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := new(ProjectItem)
+	resp, err := s.client.Do(req, p)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return p, resp, err
+}
+
+// ArchiveProject https://code.tencent.com/help/api/project#archiveProject
+func (s *ProjectsService) ArchiveProject(pid interface{}) (*ProjectItem, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/archive", pathEscape(project))
+
+	req, err := s.client.newRequest(http.MethodPost, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := new(ProjectItem)
+	resp, err := s.client.Do(req, p)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return p, resp, err
+}
+
+// UnarchiveProject https://code.tencent.com/help/api/project#unarchiveProject
+func (s *ProjectsService) UnarchiveProject(pid interface{}) (*ProjectItem, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/unarchive", pathEscape(project))
+
+	req, err := s.client.newRequest(http.MethodPost, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := new(ProjectItem)
+	resp, err := s.client.Do(req, p)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return p, resp, err
+}
+
+// DeleteProject https://code.tencent.com/help/api/project#deleteProject
+func (s *ProjectsService) DeleteProject(pid interface{}) (*Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, err
+	}
+	u := newRoute("projects/%s", pathEscape(project))
+
+	req, err := s.client.newRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
+
+type TransferProjectOptions struct {
+	NamespaceID *int64 `url:"namespace_id,omitempty" json:"namespace_id,omitempty"`
+}
+
+// TransferProject https://code.tencent.com/help/api/project#transferProject
+func (s *ProjectsService) TransferProject(pid interface{}, opts *TransferProjectOptions) (*Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, err
+	}
+	u := newRoute("projects/%s/transfer", pathEscape(project))
+
+	req, err := s.client.newRequest(http.MethodPut, u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
@@ -0,0 +1,211 @@
+package tgit
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+const (
+	rateLimitLimit     = "RateLimit-Limit"
+	rateLimitRemaining = "RateLimit-Remaining"
+	rateLimitReset     = "RateLimit-Reset"
+	retryAfter         = "Retry-After"
+)
+
+// RateLimiter throttles outgoing requests, Wait blocks until a request may be
+// sent or ctx is done.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// TokenBucket is a RateLimiter that allows bursts of up to burst requests and
+// refills at rate requests per second. It is safe for concurrent use.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		d := b.reserve()
+		if d <= 0 {
+			return nil
+		}
+
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available, otherwise it returns how long to
+// wait before the next token is due.
+func (b *TokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	if b.rate <= 0 {
+		return time.Second
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// SetRateLimiter throttles every request made through Client.Do with l,
+// retries included, pass nil to disable throttling.
+func (c *Client) SetRateLimiter(l RateLimiter) {
+	c.limiterMu.Lock()
+	defer c.limiterMu.Unlock()
+
+	c.limiter = l
+}
+
+func (c *Client) rateLimiter() RateLimiter {
+	c.limiterMu.RLock()
+	defer c.limiterMu.RUnlock()
+
+	return c.limiter
+}
+
+type limiterContextKey struct{}
+
+// retryLimiter returns a PrepareRetry that waits on the RateLimiter carried by
+// the request context before calling next, so retries are throttled like the
+// first attempt made in Client.Do.
+func retryLimiter(next retryablehttp.PrepareRetry) retryablehttp.PrepareRetry {
+	return func(req *http.Request) error {
+		if l, ok := req.Context().Value(limiterContextKey{}).(RateLimiter); ok {
+			if err := l.Wait(req.Context()); err != nil {
+				return err
+			}
+		}
+		if next != nil {
+			return next(req)
+		}
+		return nil
+	}
+}
+
+// installRetryLimiter chains retryLimiter onto the PrepareRetry of hc.
+func installRetryLimiter(hc *retryablehttp.Client) {
+	hc.PrepareRetry = retryLimiter(hc.PrepareRetry)
+}
+
+// RateLimitResetBackoff waits until the RateLimit-Reset time of 429 and 503
+// responses that carry no Retry-After header, clamped to [min, max].
+// Everything else, Retry-After included, is left to
+// retryablehttp.DefaultBackoff.
+func RateLimitResetBackoff(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	return rateLimitResetBackoff(retryablehttp.DefaultBackoff)(min, max, attemptNum, resp)
+}
+
+// rateLimitResetBackoff returns a Backoff that waits until the RateLimit-Reset
+// time like RateLimitResetBackoff and leaves everything else to next.
+func rateLimitResetBackoff(next retryablehttp.Backoff) retryablehttp.Backoff {
+	return func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+		if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) && resp.Header.Get(retryAfter) == "" {
+			if reset := parseRateLimitReset(resp.Header.Get(rateLimitReset)); !reset.IsZero() {
+				if d := time.Until(reset); d > 0 {
+					if d < min {
+						d = min
+					}
+					if d > max {
+						d = max
+					}
+					return d
+				}
+			}
+		}
+
+		return next(min, max, attemptNum, resp)
+	}
+}
+
+// installBackoff chains rateLimitResetBackoff onto the backoff of hc, a
+// backoff configured by the caller still handles everything but the
+// RateLimit-Reset waits.
+func installBackoff(hc *retryablehttp.Client) {
+	next := hc.Backoff
+	if next == nil {
+		next = retryablehttp.DefaultBackoff
+	}
+	hc.Backoff = rateLimitResetBackoff(next)
+}
+
+// parseRetryAfter parses a Retry-After value given either in seconds or as an
+// HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil {
+		if s < 0 {
+			return 0, false
+		}
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// parseRateLimitReset parses a RateLimit-Reset value given as a unix timestamp.
+func parseRateLimitReset(v string) time.Time {
+	if v == "" {
+		return time.Time{}
+	}
+	s, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(s, 0)
+}
+
+// populateRateLimitValues parses the rate limit headers of the Response.
+func (r *Response) populateRateLimitValues() {
+	if limit := r.Response.Header.Get(rateLimitLimit); limit != "" {
+		r.RateLimit, _ = strconv.Atoi(limit)
+	}
+	if remaining := r.Response.Header.Get(rateLimitRemaining); remaining != "" {
+		r.RateLimitRemaining, _ = strconv.Atoi(remaining)
+	}
+	r.RateLimitReset = parseRateLimitReset(r.Response.Header.Get(rateLimitReset))
+	r.RetryAfter, _ = parseRetryAfter(r.Response.Header.Get(retryAfter))
+}
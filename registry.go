@@ -0,0 +1,153 @@
+package tgit
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+var ErrNoClientForHost = errors.New("no client registered for host")
+
+// webRouteSegments start the part of a project web URL that addresses
+// something inside the project, e.g. ".../group/project/merge_requests/1".
+// They are only recognized after the namespace/project pair, so projects
+// named like them still parse; "/-/" starts that part anywhere.
+var webRouteSegments = map[string]bool{
+	"tree":           true,
+	"blob":           true,
+	"raw":            true,
+	"blame":          true,
+	"commit":         true,
+	"commits":        true,
+	"compare":        true,
+	"branches":       true,
+	"tags":           true,
+	"merge_requests": true,
+	"issues":         true,
+	"reviews":        true,
+	"wikis":          true,
+}
+
+// Registry holds the clients of several TGit instances keyed by host, e.g.
+// the public TGit and an internal mirror, and routes project URLs to them.
+// Clients created through the registry share one HTTP client and therefore
+// its connection pool. It is safe for concurrent use.
+type Registry struct {
+	hc *retryablehttp.Client
+
+	mu      sync.RWMutex
+	clients map[string]*Client
+}
+
+// NewRegistry returns a registry whose clients share hc, a default
+// retryablehttp client is used when hc is nil. hc is configured once here,
+// clients created through NewClient use it as it is.
+func NewRegistry(hc *retryablehttp.Client) *Registry {
+	if hc == nil {
+		hc = retryablehttp.NewClient()
+	}
+	configureHTTPClient(hc)
+	return &Registry{hc: hc, clients: make(map[string]*Client)}
+}
+
+// NewClient creates a client for the instance at baseURL using the shared
+// HTTP client, and registers it under the host of baseURL. p must not be nil.
+func (r *Registry) NewClient(baseURL string, p CredentialsProvider) (*Client, error) {
+	if p == nil {
+		return nil, fmt.Errorf("no credentials provider for %s", baseURL)
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("base URL %q has no host", baseURL)
+	}
+
+	c, err := newSharedClient(r.hc)
+	if err != nil {
+		return nil, err
+	}
+	c.credentials = p
+	if err := c.SetBaseURL(baseURL); err != nil {
+		return nil, err
+	}
+
+	r.Register(u.Hostname(), c)
+	return c, nil
+}
+
+// Register adds c under host. A client may be registered under several hosts,
+// e.g. when SSH clones use a different host name than the web UI.
+func (r *Registry) Register(host string, c *Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clients[strings.ToLower(host)] = c
+}
+
+// Client returns the client registered for host.
+func (r *Registry) Client(host string) (*Client, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.clients[strings.ToLower(host)]
+	return c, ok
+}
+
+// Resolve returns the client and the "namespace/path" project identifier for
+// a project web URL, HTTP(S) clone URL or SSH clone URL, such as the
+// WebURL, HTTPSURLToRepo and SSHURLToRepo of a ProjectItem.
+func (r *Registry) Resolve(rawURL string) (*Client, string, error) {
+	host, project, err := ParseProjectURL(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	c, ok := r.Client(host)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %s", ErrNoClientForHost, host)
+	}
+	return c, project, nil
+}
+
+// ParseProjectURL splits a project web or clone URL into its host and
+// "namespace/path" project identifier. Besides regular URLs it accepts the
+// scp-like "git@host:namespace/path.git" form used for SSH clones.
+func ParseProjectURL(rawURL string) (host, project string, err error) {
+	rawURL = strings.TrimSpace(rawURL)
+
+	var p string
+	if i := strings.Index(rawURL, ":"); i > 0 && !strings.Contains(rawURL[:i], "/") && !strings.HasPrefix(rawURL[i:], "://") {
+		// scp-like syntax: [user@]host:path
+		host = rawURL[:i]
+		if j := strings.LastIndex(host, "@"); j >= 0 {
+			host = host[j+1:]
+		}
+		p = rawURL[i+1:]
+	} else {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return "", "", err
+		}
+		host, p = u.Hostname(), u.Path
+	}
+
+	var segs []string
+	for _, seg := range strings.Split(strings.Trim(p, "/"), "/") {
+		if seg == "-" || len(segs) >= 2 && webRouteSegments[seg] {
+			break
+		}
+		segs = append(segs, seg)
+	}
+	project = strings.TrimSuffix(strings.Join(segs, "/"), ".git")
+
+	if host == "" || !strings.Contains(project, "/") {
+		return "", "", fmt.Errorf("%q is not a project URL", rawURL)
+	}
+	return strings.ToLower(host), project, nil
+}
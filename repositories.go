@@ -1,7 +1,6 @@
 package tgit
 
 import (
-	"fmt"
 	"net/http"
 )
 
@@ -9,6 +8,8 @@ type RepositoriesService struct {
 	client *Client
 }
 
+// Diff is the change of a single file, shared by compares and merge request
+// changes.
 type Diff struct {
 	OldPath     string `json:"old_path,omitempty"`
 	NewPath     string `json:"new_path,omitempty"`
@@ -45,9 +46,9 @@ func (s *RepositoriesService) Compare(pid interface{}, opts *CompareOptions) (*C
 	if err != nil {
 		return nil, nil, err
 	}
-	u := fmt.Sprintf("projects/%s/repository/compare", pathEscape(project))
+	u := newRoute("projects/%s/repository/compare", pathEscape(project))
 
-	req, err := s.client.NewRequest(http.MethodGet, u, opts)
+	req, err := s.client.newRequest(http.MethodGet, u, opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -60,3 +61,37 @@ func (s *RepositoriesService) Compare(pid interface{}, opts *CompareOptions) (*C
 
 	return c, resp, err
 }
+
+type Contributor struct {
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Commits   int    `json:"commits"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+func (c Contributor) String() string {
+	return Stringify(c)
+}
+
+// ListContributors https://code.tencent.com/help/api/repository#getContributors
+func (s *RepositoriesService) ListContributors(pid interface{}) ([]*Contributor, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/repository/contributors", pathEscape(project))
+
+	req, err := s.client.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cs []*Contributor
+	resp, err := s.client.Do(req, &cs)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return cs, resp, err
+}
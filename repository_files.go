@@ -34,9 +34,9 @@ func (s *RepositoryFilesService) GetFile(pid interface{}, opts *GetFileOptions)
 	if err != nil {
 		return nil, nil, err
 	}
-	u := fmt.Sprintf("projects/%s/repository/files", project)
+	u := newRoute("projects/%s/repository/files", project)
 
-	req, err := s.client.NewRequest(http.MethodGet, u, opts)
+	req, err := s.client.newRequest(http.MethodGet, u, opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -69,9 +69,9 @@ func (s *RepositoryFilesService) CreateFile(pid interface{}, opts *CreateFileOpt
 	if err != nil {
 		return nil, nil, err
 	}
-	u := fmt.Sprintf("projects/%s/repository/files", project)
+	u := newRoute("projects/%s/repository/files", project)
 
-	req, err := s.client.NewRequest(http.MethodPost, u, opts)
+	req, err := s.client.newRequest(http.MethodPost, u, opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -98,9 +98,9 @@ func (s *RepositoryFilesService) UpdateFile(pid interface{}, opts *UpdateFileOpt
 	if err != nil {
 		return nil, nil, err
 	}
-	u := fmt.Sprintf("projects/%s/repository/files", project)
+	u := newRoute("projects/%s/repository/files", project)
 
-	req, err := s.client.NewRequest(http.MethodPut, u, opts)
+	req, err := s.client.newRequest(http.MethodPut, u, opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -125,9 +125,9 @@ func (s *RepositoryFilesService) DeleteFile(pid interface{}, opts *DeleteFileOpt
 	if err != nil {
 		return nil, nil, err
 	}
-	u := fmt.Sprintf("projects/%s/repository/files", project)
+	u := newRoute("projects/%s/repository/files", project)
 
-	req, err := s.client.NewRequest(http.MethodDelete, u, opts)
+	req, err := s.client.newRequest(http.MethodDelete, u, opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -140,3 +140,64 @@ func (s *RepositoryFilesService) DeleteFile(pid interface{}, opts *DeleteFileOpt
 
 	return f, resp, err
 }
+
+// BlameRange is a run of consecutive lines last changed by Commit. StartLine
+// and EndLine are 1-based and inclusive, they are computed by the client from
+// the order of the ranges.
+type BlameRange struct {
+	Commit    *Commit  `json:"commit"`
+	Lines     []string `json:"lines"`
+	StartLine int      `json:"-"`
+	EndLine   int      `json:"-"`
+}
+
+func (b BlameRange) String() string {
+	return Stringify(b)
+}
+
+type GetFileBlameOptions struct {
+	Ref      *string `url:"ref,omitempty"`
+	FilePath *string `url:"file_path"`
+}
+
+// GetFileBlame https://code.tencent.com/help/api/repository#getBlame
+func (s *RepositoryFilesService) GetFileBlame(pid interface{}, opts *GetFileBlameOptions) ([]*BlameRange, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts == nil || opts.FilePath == nil || *opts.FilePath == "" {
+		return nil, nil, fmt.Errorf("FilePath must be a non-empty string")
+	}
+	u := newRoute("projects/%s/repository/blame", pathEscape(project))
+
+	req, err := s.client.newRequest(http.MethodGet, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var bs []*BlameRange
+	resp, err := s.client.Do(req, &bs)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	line := 1
+	for _, b := range bs {
+		b.StartLine = line
+		line += len(b.Lines)
+		b.EndLine = line - 1
+	}
+
+	return bs, resp, err
+}
+
+// BlameLine returns the range containing the 1-based line, or nil.
+func BlameLine(ranges []*BlameRange, line int) *BlameRange {
+	for _, b := range ranges {
+		if line >= b.StartLine && line <= b.EndLine {
+			return b
+		}
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package tgit
+
+import (
+	"net/http"
+)
+
+// ReviewsService handles TGit code reviews over commit ranges, which exist
+// independently of merge requests.
+type ReviewsService struct {
+	client *Client
+}
+
+type Review struct {
+	ID             int64                 `json:"id"`
+	Iid            int64                 `json:"iid"`
+	ProjectID      int64                 `json:"project_id"`
+	Title          string                `json:"title"`
+	Description    string                `json:"description"`
+	State          string                `json:"state"`
+	ReviewableType string                `json:"reviewable_type"`
+	ReviewableID   int64                 `json:"reviewable_id"`
+	SourceBranch   string                `json:"source_branch"`
+	SourceCommit   string                `json:"source_commit"`
+	TargetBranch   string                `json:"target_branch"`
+	TargetCommit   string                `json:"target_commit"`
+	Author         *MergeRequestUser     `json:"author"`
+	Reviewers      []*MergeRequestViewer `json:"reviewers"`
+	Labels         []string              `json:"labels"`
+	CreatedAt      *Time                 `json:"created_at"`
+	UpdatedAt      *Time                 `json:"updated_at"`
+}
+
+func (r Review) String() string {
+	return Stringify(r)
+}
+
+type ListReviewsOptions struct {
+	ListOptions
+	State    *string `url:"state,omitempty" json:"state,omitempty"`
+	AuthorID *int64  `url:"author_id,omitempty" json:"author_id,omitempty"`
+	OrderBy  *string `url:"order_by,omitempty" json:"order_by,omitempty"`
+	Sort     *string `url:"sort,omitempty" json:"sort,omitempty"`
+}
+
+// ListReviews https://code.tencent.com/help/api/review#listReviews
+func (s *ReviewsService) ListReviews(pid interface{}, opts *ListReviewsOptions) ([]*Review, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/reviews", pathEscape(project))
+
+	req, err := s.client.newRequest(http.MethodGet, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var r []*Review
+	resp, err := s.client.Do(req, &r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return r, resp, err
+}
+
+// GetReview https://code.tencent.com/help/api/review#getReview
+func (s *ReviewsService) GetReview(pid interface{}, review int64) (*Review, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/review/%d", pathEscape(project), review)
+
+	req, err := s.client.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := new(Review)
+	resp, err := s.client.Do(req, r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return r, resp, err
+}
+
+// CreateReviewOptions selects the reviewed commit range: the changes from
+// TargetCommit (exclusive) to SourceCommit (inclusive), or between the heads
+// of the branches when no commits are given.
+type CreateReviewOptions struct {
+	Title        *string  `url:"title,omitempty" json:"title,omitempty"`
+	Description  *string  `url:"description,omitempty" json:"description,omitempty"`
+	SourceBranch *string  `url:"source_branch,omitempty" json:"source_branch,omitempty"`
+	SourceCommit *string  `url:"source_commit,omitempty" json:"source_commit,omitempty"`
+	TargetBranch *string  `url:"target_branch,omitempty" json:"target_branch,omitempty"`
+	TargetCommit *string  `url:"target_commit,omitempty" json:"target_commit,omitempty"`
+	ReviewerIDs  []int64  `url:"reviewer_ids,comma,omitempty" json:"reviewer_ids,omitempty"`
+	Labels       []string `url:"labels,comma,omitempty" json:"labels,omitempty"`
+}
+
+// CreateReview https://code.tencent.com/help/api/review#createReview
+func (s *ReviewsService) CreateReview(pid interface{}, opts *CreateReviewOptions) (*Review, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/review", pathEscape(project))
+
+	req, err := s.client.newRequest(http.MethodPost, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := new(Review)
+	resp, err := s.client.Do(req, r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return r, resp, err
+}
+
+type UpdateReviewOptions struct {
+	Title       *string  `url:"title,omitempty" json:"title,omitempty"`
+	Description *string  `url:"description,omitempty" json:"description,omitempty"`
+	Labels      []string `url:"labels,comma,omitempty" json:"labels,omitempty"`
+	// StateEvent is "close" or "reopen".
+	StateEvent *string `url:"state_event,omitempty" json:"state_event,omitempty"`
+}
+
+// UpdateReview https://code.tencent.com/help/api/review#updateReview
+func (s *ReviewsService) UpdateReview(pid interface{}, review int64, opts *UpdateReviewOptions) (*Review, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/review/%d", pathEscape(project), review)
+
+	req, err := s.client.newRequest(http.MethodPut, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := new(Review)
+	resp, err := s.client.Do(req, r)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return r, resp, err
+}
+
+type AddReviewersOptions struct {
+	ReviewerIDs []int64 `url:"reviewer_ids,comma,omitempty" json:"reviewer_ids,omitempty"`
+	// Necessary marks the reviewers as necessary instead of suggested.
+	Necessary *bool `url:"necessary,omitempty" json:"necessary,omitempty"`
+}
+
+// AddReviewers https://code.tencent.com/help/api/review#addReviewers
+func (s *ReviewsService) AddReviewers(pid interface{}, review int64, opts *AddReviewersOptions) ([]*MergeRequestViewer, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/review/%d/reviewers", pathEscape(project), review)
+
+	req, err := s.client.newRequest(http.MethodPost, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var v []*MergeRequestViewer
+	resp, err := s.client.Do(req, &v)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return v, resp, err
+}
+
+// RemoveReviewer https://code.tencent.com/help/api/review#removeReviewer
+func (s *ReviewsService) RemoveReviewer(pid interface{}, review, reviewer int64) (*Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, err
+	}
+	u := newRoute("projects/%s/review/%d/reviewers/%d", pathEscape(project), review, reviewer)
+
+	req, err := s.client.newRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
+
+type ReviewEventValue string
+
+const (
+	ReviewApprove       ReviewEventValue = "approve"
+	ReviewComment       ReviewEventValue = "comment"
+	ReviewRequireChange ReviewEventValue = "require_change"
+	ReviewDeny          ReviewEventValue = "deny"
+	ReviewReset         ReviewEventValue = "reset"
+)
+
+type SetReviewStateOptions struct {
+	ReviewerEvent *ReviewEventValue `url:"reviewer_event,omitempty" json:"reviewer_event,omitempty"`
+	Summary       *string           `url:"summary,omitempty" json:"summary,omitempty"`
+}
+
+// SetReviewState changes the review state of the authenticated user as a
+// reviewer, e.g. to approve a review.
+// tgit doc: https://code.tencent.com/help/api/review#setReviewState
+func (s *ReviewsService) SetReviewState(pid interface{}, review int64, opts *SetReviewStateOptions) (*MergeRequestViewer, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/review/%d/reviewer/summary", pathEscape(project), review)
+
+	req, err := s.client.newRequest(http.MethodPut, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v := new(MergeRequestViewer)
+	resp, err := s.client.Do(req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return v, resp, err
+}
+
+type ReviewNote struct {
+	ID        int64             `json:"id"`
+	Body      string            `json:"body"`
+	Author    *MergeRequestUser `json:"author"`
+	Path      string            `json:"path"`
+	Line      int               `json:"line"`
+	LineType  string            `json:"line_type"`
+	CommitID  string            `json:"commit_id"`
+	Resolved  bool              `json:"resolved"`
+	CreatedAt *Time             `json:"created_at"`
+	UpdatedAt *Time             `json:"updated_at"`
+}
+
+func (n ReviewNote) String() string {
+	return Stringify(n)
+}
+
+type ListReviewNotesOptions struct {
+	ListOptions
+}
+
+// ListReviewNotes https://code.tencent.com/help/api/review#listReviewNotes
+func (s *ReviewsService) ListReviewNotes(pid interface{}, review int64, opts *ListReviewNotesOptions) ([]*ReviewNote, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/review/%d/notes", pathEscape(project), review)
+
+	req, err := s.client.newRequest(http.MethodGet, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var n []*ReviewNote
+	resp, err := s.client.Do(req, &n)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return n, resp, err
+}
+
+// CreateReviewNoteOptions posts a general comment, or a line comment when
+// Path and Line are set.
+type CreateReviewNoteOptions struct {
+	Body *string `url:"body,omitempty" json:"body,omitempty"`
+	Path *string `url:"path,omitempty" json:"path,omitempty"`
+	Line *int    `url:"line,omitempty" json:"line,omitempty"`
+	// LineType is "new" or "old", the side of the diff Line refers to.
+	LineType *string `url:"line_type,omitempty" json:"line_type,omitempty"`
+}
+
+// CreateReviewNote https://code.tencent.com/help/api/review#createReviewNote
+func (s *ReviewsService) CreateReviewNote(pid interface{}, review int64, opts *CreateReviewNoteOptions) (*ReviewNote, *Response, error) {
+	project, err := parseID(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := newRoute("projects/%s/review/%d/notes", pathEscape(project), review)
+
+	req, err := s.client.newRequest(http.MethodPost, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	n := new(ReviewNote)
+	resp, err := s.client.Do(req, n)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return n, resp, err
+}
@@ -0,0 +1,102 @@
+package tgit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var ErrMissingCredentials = errors.New("username and password are required to create a session")
+
+// Session is the user returned by the session endpoint together with its
+// private token.
+type Session struct {
+	User
+	PrivateToken string `json:"private_token"`
+}
+
+func (s Session) String() string {
+	return Stringify(s)
+}
+
+type createSessionOptions struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+// Login exchanges the username and password of a basic auth client for a
+// private token, which is used for all subsequent calls. Client.Do logs in on
+// first use and once more when the server rejects the token, so calling Login
+// is only needed to check credentials early or to obtain the token.
+// tgit doc: https://code.tencent.com/help/api/session
+func (c *Client) Login(ctx context.Context) (*Session, error) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	return c.loginLocked(ctx)
+}
+
+func (c *Client) loginLocked(ctx context.Context) (*Session, error) {
+	if c.username == "" || c.password == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	req, err := c.newRequestWithContext(ctx, http.MethodPost, newRoute("session"), &createSessionOptions{
+		Login:    c.username,
+		Password: c.password,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Bypass Do, which would try to log in again.
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckResponse(resp); err != nil {
+		return nil, err
+	}
+
+	s := new(Session)
+	if err := c.decodeJSON(resp.Body, s); err != nil {
+		return nil, err
+	}
+	if s.PrivateToken == "" {
+		return nil, errors.New("session response is missing private_token")
+	}
+
+	c.credentials = &sessionCredentials{client: c, token: s.PrivateToken}
+	return s, nil
+}
+
+// sessionCredentials hands out the private token of a session and logs in
+// again when the server rejects it.
+type sessionCredentials struct {
+	client *Client
+	token  string
+}
+
+func (s *sessionCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	return Credentials{Token: s.token}, nil
+}
+
+// refresh logs in again after the server rejected stale. When another request
+// logged in in the meantime, or the credentials were replaced, the current
+// ones are returned instead.
+func (s *sessionCredentials) refresh(ctx context.Context, stale string) (Credentials, error) {
+	c := s.client
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	cur, ok := c.credentials.(*sessionCredentials)
+	if c.credentials != nil && (!ok || cur.token != stale) {
+		return c.credentials.Credentials(ctx)
+	}
+	if _, err := c.loginLocked(ctx); err != nil {
+		return Credentials{}, err
+	}
+	return c.credentials.Credentials(ctx)
+}
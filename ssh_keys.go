@@ -0,0 +1,194 @@
+package tgit
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var ErrInvalidPublicKey = errors.New("invalid SSH public key")
+
+var publicKeyTypes = map[string]bool{
+	"ssh-rsa":                            true,
+	"ssh-dss":                            true,
+	"ssh-ed25519":                        true,
+	"ecdsa-sha2-nistp256":                true,
+	"ecdsa-sha2-nistp384":                true,
+	"ecdsa-sha2-nistp521":                true,
+	"sk-ssh-ed25519@openssh.com":         true,
+	"sk-ecdsa-sha2-nistp256@openssh.com": true,
+}
+
+type SSHKey struct {
+	ID        int64  `json:"id"`
+	Title     string `json:"title"`
+	Key       string `json:"key"`
+	CreatedAt *Time  `json:"created_at"`
+}
+
+func (k SSHKey) String() string {
+	return Stringify(k)
+}
+
+// parsePublicKey checks that key is in the authorized_keys format
+// "<type> <base64 blob> [comment]" and returns the decoded blob.
+func parsePublicKey(key string) ([]byte, error) {
+	fields := strings.Fields(key)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("%w: expected \"<type> <base64>\"", ErrInvalidPublicKey)
+	}
+	if !publicKeyTypes[fields[0]] {
+		return nil, fmt.Errorf("%w: unsupported key type %q", ErrInvalidPublicKey, fields[0])
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+
+	// The blob starts with the length prefixed key type, which must match the
+	// type given in the first field.
+	if len(blob) < 4 {
+		return nil, fmt.Errorf("%w: key data too short", ErrInvalidPublicKey)
+	}
+	n := binary.BigEndian.Uint32(blob)
+	if uint64(len(blob)) < 4+uint64(n) || string(blob[4:4+n]) != fields[0] {
+		return nil, fmt.Errorf("%w: key data does not match type %q", ErrInvalidPublicKey, fields[0])
+	}
+
+	return blob, nil
+}
+
+// ValidatePublicKey reports whether key looks like a valid SSH public key.
+func ValidatePublicKey(key string) error {
+	_, err := parsePublicKey(key)
+	return err
+}
+
+// PublicKeyFingerprint returns the SHA256 fingerprint of key in the format
+// printed by ssh-keygen -l, e.g. "SHA256:I0BN2ucio/...".
+func PublicKeyFingerprint(key string) (string, error) {
+	blob, err := parsePublicKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]), nil
+}
+
+// PublicKeyFingerprintMD5 returns the legacy colon separated MD5 fingerprint
+// of key.
+func PublicKeyFingerprintMD5(key string) (string, error) {
+	blob, err := parsePublicKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	sum := md5.Sum(blob)
+	hex := make([]string, len(sum))
+	for i, b := range sum {
+		hex[i] = fmt.Sprintf("%02x", b)
+	}
+	return strings.Join(hex, ":"), nil
+}
+
+// ListSSHKeys lists the SSH keys of the authenticated user.
+// tgit doc: https://code.tencent.com/help/api/user#listSSHKeys
+func (s *UsersService) ListSSHKeys() ([]*SSHKey, *Response, error) {
+	req, err := s.client.newRequest(http.MethodGet, newRoute("user/keys"), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var k []*SSHKey
+	resp, err := s.client.Do(req, &k)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return k, resp, err
+}
+
+// ListSSHKeysForUser https://code.tencent.com/help/api/user#listSSHKeysForUser
+func (s *UsersService) ListSSHKeysForUser(uid int64) ([]*SSHKey, *Response, error) {
+	u := newRoute("users/%d/keys", uid)
+
+	req, err := s.client.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var k []*SSHKey
+	resp, err := s.client.Do(req, &k)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return k, resp, err
+}
+
+// GetSSHKey https://code.tencent.com/help/api/user#getSSHKey
+func (s *UsersService) GetSSHKey(key int64) (*SSHKey, *Response, error) {
+	u := newRoute("user/keys/%d", key)
+
+	req, err := s.client.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k := new(SSHKey)
+	resp, err := s.client.Do(req, k)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return k, resp, err
+}
+
+type AddSSHKeyOptions struct {
+	Title *string `url:"title,omitempty" json:"title,omitempty"`
+	Key   *string `url:"key,omitempty" json:"key,omitempty"`
+}
+
+// AddSSHKey adds a key to the authenticated user, the key is validated before
+// it is sent.
+// tgit doc: https://code.tencent.com/help/api/user#addSSHKey
+func (s *UsersService) AddSSHKey(opts *AddSSHKeyOptions) (*SSHKey, *Response, error) {
+	if opts == nil || opts.Key == nil {
+		return nil, nil, fmt.Errorf("%w: key is required", ErrInvalidPublicKey)
+	}
+	if err := ValidatePublicKey(*opts.Key); err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.newRequest(http.MethodPost, newRoute("user/keys"), opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k := new(SSHKey)
+	resp, err := s.client.Do(req, k)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return k, resp, err
+}
+
+// DeleteSSHKey https://code.tencent.com/help/api/user#deleteSSHKey
+func (s *UsersService) DeleteSSHKey(key int64) (*Response, error) {
+	u := newRoute("user/keys/%d", key)
+
+	req, err := s.client.newRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
@@ -1,7 +1,6 @@
 package tgit
 
 import (
-	"fmt"
 	"net/http"
 	"net/url"
 )
@@ -29,9 +28,9 @@ func (s *TagsService) ListTags(pid interface{}, opts *ListTagsOptions) ([]*Tag,
 	if err != nil {
 		return nil, nil, err
 	}
-	u := fmt.Sprintf("projects/%s/repository/tags", pathEscape(project))
+	u := newRoute("projects/%s/repository/tags", pathEscape(project))
 
-	req, err := s.client.NewRequest(http.MethodGet, u, opts)
+	req, err := s.client.newRequest(http.MethodGet, u, opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -50,9 +49,9 @@ func (s *TagsService) GetTag(pid interface{}, tag string) (*Tag, *Response, erro
 	if err != nil {
 		return nil, nil, err
 	}
-	u := fmt.Sprintf("projects/%s/repository/tags/%s", pathEscape(project), url.PathEscape(tag))
+	u := newRoute("projects/%s/repository/tags/%s", pathEscape(project), url.PathEscape(tag))
 
-	req, err := s.client.NewRequest(http.MethodGet, u, nil)
+	req, err := s.client.newRequest(http.MethodGet, u, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/liwenqiu/go-tgit"
+)
+
+func TestUsersService_RotatePersonalAccessToken(t *testing.T) {
+	var revokedWith string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v3/user/personal_access_tokens":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":2,"name":"ci","scopes":["api"],"active":true,"token":"new"}`)
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/v3/user/personal_access_tokens/1":
+			revokedWith = r.Header.Get("PRIVATE-TOKEN")
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := tgit.NewClient(retryablehttp.NewClient(), "old")
+	c.SetBaseURL(ts.URL)
+
+	name := "ci"
+	tok, _, err := c.Users.RotatePersonalAccessToken(1, &tgit.CreatePersonalAccessTokenOptions{
+		Name:   &name,
+		Scopes: []string{"api"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.ID != 2 || tok.Token != "new" {
+		t.Fatalf("unexpected token %v", tok)
+	}
+	if revokedWith != "new" {
+		t.Fatalf("expected the old token to be revoked with the new one, got %q", revokedWith)
+	}
+}
+
+func TestUsersService_RotatePersonalAccessTokenWithoutValue(t *testing.T) {
+	var revoked bool
+	var lastToken string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastToken = r.Header.Get("PRIVATE-TOKEN")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v3/user/personal_access_tokens":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":2,"name":"ci","scopes":["api"],"active":true}`)
+		case r.Method == http.MethodDelete:
+			revoked = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			fmt.Fprint(w, `{"id":1}`)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := tgit.NewClient(retryablehttp.NewClient(), "old")
+	c.SetBaseURL(ts.URL)
+
+	name := "ci"
+	if _, _, err := c.Users.RotatePersonalAccessToken(1, &tgit.CreatePersonalAccessTokenOptions{Name: &name}); err == nil {
+		t.Fatal("expected an error for a token without value")
+	}
+	if revoked {
+		t.Fatal("the old token must not be revoked")
+	}
+	if _, _, err := c.Users.Get(""); err != nil || lastToken != "old" {
+		t.Fatalf("expected the client to keep the old token, sent %q: %v", lastToken, err)
+	}
+}
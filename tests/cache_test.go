@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/liwenqiu/go-tgit"
+)
+
+func TestClient_Cache(t *testing.T) {
+	var notModified int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("X-Total", "1")
+		fmt.Fprint(w, `[{"name":"master"}]`)
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	disk, err := tgit.NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cache := range []tgit.Cache{tgit.NewMemoryCache(10), disk} {
+		notModified = 0
+
+		c, _ := tgit.NewClient(retryablehttp.NewClient(), "token")
+		c.SetBaseURL(ts.URL)
+		c.SetCache(cache)
+
+		for i := 0; i < 2; i++ {
+			b, resp, err := c.Branches.ListBranches(1, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(b) != 1 || b[0].Name != "master" || resp.TotalItems != 1 {
+				t.Fatalf("%T: unexpected branches %v, total %d", cache, b, resp.TotalItems)
+			}
+		}
+		if notModified != 1 {
+			t.Fatalf("%T: expected one conditional hit, got %d", cache, notModified)
+		}
+	}
+}
+
+func TestClient_NotModifiedWithoutCache(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer ts.Close()
+
+	c, _ := tgit.NewClient(retryablehttp.NewClient(), "token")
+	c.SetBaseURL(ts.URL)
+
+	if _, _, err := c.Branches.ListBranches(1, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_SetCacheConcurrently(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `[{"name":"master"}]`)
+	}))
+	defer ts.Close()
+
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	c, _ := tgit.NewClient(hc, "token")
+	c.SetBaseURL(ts.URL)
+
+	// Disabling the cache while requests are in flight must not make them
+	// use a nil cache.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				c.SetCache(tgit.NewMemoryCache(10))
+			} else {
+				c.SetCache(nil)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, _, err := c.Branches.ListBranches(1, nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestMemoryCache_Evict(t *testing.T) {
+	m := tgit.NewMemoryCache(2)
+	m.Set("a", &tgit.CacheEntry{})
+	m.Set("b", &tgit.CacheEntry{})
+	m.Get("a")
+	m.Set("c", &tgit.CacheEntry{})
+
+	if _, ok := m.Get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if _, ok := m.Get("a"); !ok {
+		t.Fatal("expected a to be kept")
+	}
+}
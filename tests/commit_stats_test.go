@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/liwenqiu/go-tgit"
+	"github.com/liwenqiu/go-tgit/tgittest"
+)
+
+func TestCommitsService_CollectCommitStats(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+
+	at := func(day, hour int) *tgit.Time {
+		return &tgit.Time{Time: time.Date(2021, 3, day, hour, 0, 0, 0, time.UTC)}
+	}
+	commits := []*tgit.Commit{
+		{ID: "a1", AuthorEmail: "alice@example.com", AuthoredDate: at(1, 10), CommittedDate: at(1, 10), ParentIDs: []string{"p"}},
+		{ID: "b1", AuthorEmail: "bob@example.com", AuthoredDate: at(1, 20), CommittedDate: at(1, 20), ParentIDs: []string{"a1"}},
+		{ID: "m1", AuthorEmail: "bob@example.com", AuthoredDate: at(2, 1), CommittedDate: at(2, 1), ParentIDs: []string{"a1", "b1"}},
+		{ID: "a2", AuthorEmail: "alice@example.com", AuthoredDate: at(5, 1), CommittedDate: at(5, 1), ParentIDs: []string{"m1"}},
+	}
+	for _, c := range commits {
+		s.AddCommit(1, c)
+	}
+	s.AddCommitDiff(1, "a1", []*tgit.Diff{
+		{NewPath: "main.go", Additions: 10, Deletions: 2},
+		{NewPath: "README.md", Additions: 1},
+	})
+	s.AddCommitDiff(1, "b1", []*tgit.Diff{
+		{NewPath: "main.go", Diff: "--- a/main.go\n+++ b/main.go\n@@ -1,2 +1,2 @@\n-old\n--- removed comment\n+new\n context"},
+	})
+	s.AddCommitDiff(1, "m1", []*tgit.Diff{{NewPath: "main.go", Additions: 100}})
+	s.AddCommitDiff(1, "a2", []*tgit.Diff{{NewPath: "main.go", Additions: 50}})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	until := time.Date(2021, 3, 3, 0, 0, 0, 0, time.UTC)
+	stats, err := c.Commits.CollectCommitStats(context.Background(), "group/project", &tgit.ListCommitsOptions{
+		ListOptions: tgit.ListOptions{PerPage: 1},
+		Until:       &until,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := (tgit.ActivityStats{Commits: 2, Additions: 12, Deletions: 4}); stats.Total != want {
+		t.Fatalf("unexpected total %v", stats.Total)
+	}
+	if a := stats.Authors["alice@example.com"]; a == nil || a.Commits != 1 || a.Additions != 11 {
+		t.Fatalf("unexpected alice stats %v", a)
+	}
+	if b := stats.Authors["bob@example.com"]; b == nil || b.Commits != 1 || b.Additions != 1 || b.Deletions != 2 {
+		t.Fatalf("unexpected bob stats %v", b)
+	}
+	// 20:00 UTC on the 1st is the 2nd in China Standard Time.
+	if d := stats.Days["2021-03-01"]; d == nil || d.Commits != 1 {
+		t.Fatalf("unexpected day stats %v", stats.Days)
+	}
+	if d := stats.Days["2021-03-02"]; d == nil || d.Commits != 1 {
+		t.Fatalf("unexpected day stats %v", stats.Days)
+	}
+	if p := stats.Paths["main.go"]; p == nil || p.Commits != 2 || p.Additions != 11 {
+		t.Fatalf("unexpected path stats %v", p)
+	}
+}
+
+func TestCommitsService_CollectCommitStatsCanceled(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+	s.AddCommit(1, &tgit.Commit{ID: "a1"})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Commits.CollectCommitStats(ctx, "group/project", nil); err == nil {
+		t.Fatal("expected an error for a canceled context")
+	}
+}
+
+func TestRepositoriesService_ListContributors(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+	s.AddCommit(1, &tgit.Commit{ID: "a2", AuthorName: "alice", AuthorEmail: "alice@example.com"})
+	s.AddCommit(1, &tgit.Commit{ID: "b1", AuthorName: "bob", AuthorEmail: "bob@example.com"})
+	s.AddCommit(1, &tgit.Commit{ID: "a1", AuthorName: "alice", AuthorEmail: "alice@example.com"})
+	s.AddCommitDiff(1, "a2", []*tgit.Diff{{NewPath: "main.go", Additions: 30, Deletions: 7}})
+	s.AddCommitDiff(1, "a1", []*tgit.Diff{{NewPath: "main.go", Additions: 10}})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs, _, err := c.Repositories.ListContributors("group/project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 2 || cs[0].Email != "alice@example.com" || cs[0].Commits != 2 || cs[0].Additions != 40 || cs[0].Deletions != 7 || cs[1].Commits != 1 {
+		t.Fatalf("unexpected contributors %v", cs)
+	}
+
+	if _, _, err := c.Repositories.ListContributors("group/missing"); err == nil {
+		t.Fatal("expected an error for an unknown project")
+	}
+}
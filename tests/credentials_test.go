@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/liwenqiu/go-tgit"
+	"github.com/liwenqiu/go-tgit/tgittest"
+)
+
+func TestFileCredentials_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("one\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := tgit.NewFileCredentials(path, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := f.Credentials(context.Background()); c.Token != "one" {
+		t.Fatalf("unexpected token %q", c.Token)
+	}
+
+	if err := os.WriteFile(path, []byte("two"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, time.Now(), time.Now().Add(time.Second))
+
+	if c, _ := f.Credentials(context.Background()); c.Token != "two" {
+		t.Fatalf("unexpected token %q", c.Token)
+	}
+}
+
+func TestClient_SetCredentialsConcurrently(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.SetCurrentUser(&tgit.User{ID: 1, Username: "me"})
+
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	c, _ := tgit.NewClientWithCredentials(hc, tgit.EnvCredentials{Name: "TGIT_TEST_TOKEN"})
+	c.SetBaseURL(s.URL)
+
+	if _, _, err := c.Users.Get(""); err == nil {
+		t.Fatal("expected an error for a missing environment variable")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c.SetToken("token")
+		}()
+		go func() {
+			defer wg.Done()
+			c.SetCredentials(tgit.CredentialsFunc(func(ctx context.Context) (tgit.Credentials, error) {
+				return tgit.Credentials{Token: "token"}, nil
+			}))
+			if _, _, err := c.Users.Get(""); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
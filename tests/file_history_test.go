@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/liwenqiu/go-tgit"
+	"github.com/liwenqiu/go-tgit/tgittest"
+)
+
+func TestCommitsService_FileHistory(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+
+	// Newest first, like the real service. x was committed after the rename
+	// on a branch forked from c1, so it is not part of the history of c2.
+	for _, c := range []*tgit.Commit{
+		{ID: "c5", ParentIDs: []string{"c4"}},
+		{ID: "c4", ParentIDs: []string{"c3"}},
+		{ID: "x", ParentIDs: []string{"c1"}},
+		{ID: "c3", ParentIDs: []string{"c2"}},
+		{ID: "c2", ParentIDs: []string{"c1"}},
+		{ID: "c1", ParentIDs: []string{"c0"}},
+		{ID: "c0"},
+	} {
+		s.AddCommit(1, c)
+	}
+	s.AddCommitDiff(1, "c5", []*tgit.Diff{{OldPath: "other.go", NewPath: "other.go"}})
+	s.AddCommitDiff(1, "c4", []*tgit.Diff{{OldPath: "new.go", NewPath: "new.go"}})
+	s.AddCommitDiff(1, "x", []*tgit.Diff{{OldPath: "old.go", NewPath: "old.go"}})
+	s.AddCommitDiff(1, "c3", []*tgit.Diff{{OldPath: "old.go", NewPath: "new.go", RenamedFile: true}})
+	s.AddCommitDiff(1, "c2", []*tgit.Diff{{OldPath: "old.go", NewPath: "old.go"}})
+	s.AddCommitDiff(1, "c1", []*tgit.Diff{{OldPath: "old.go", NewPath: "old.go"}})
+	s.AddCommitDiff(1, "c0", []*tgit.Diff{{NewPath: "old.go", NewFile: true}})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, perPage := range []int{1, 0} {
+		history, err := c.Commits.FileHistory(context.Background(), "group/project", "new.go", &tgit.ListCommitsOptions{ListOptions: tgit.ListOptions{PerPage: perPage}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		for _, r := range history {
+			got = append(got, r.Commit.ID+":"+r.Path)
+		}
+		if fmt.Sprint(got) != "[c4:new.go c3:new.go c2:old.go c1:old.go c0:old.go]" {
+			t.Fatalf("per page %d: unexpected history %v", perPage, got)
+		}
+	}
+}
+
+func TestRepositoryFilesService_GetFileBlame(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+	s.AddBlame(1, "", "main.go", []*tgit.BlameRange{
+		{Commit: &tgit.Commit{ID: "aaa", AuthorName: "alice"}, Lines: []string{"package main", ""}},
+		{Commit: &tgit.Commit{ID: "bbb", AuthorName: "bob"}, Lines: []string{"func main() {}"}},
+	})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filePath := "main.go"
+	ranges, _, err := c.RepositoryFiles.GetFileBlame("group/project", &tgit.GetFileBlameOptions{FilePath: &filePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 2 || ranges[0].StartLine != 1 || ranges[0].EndLine != 2 || ranges[1].StartLine != 3 || ranges[1].EndLine != 3 {
+		t.Fatalf("unexpected ranges %v", ranges)
+	}
+	if b := tgit.BlameLine(ranges, 3); b == nil || b.Commit.AuthorName != "bob" {
+		t.Fatalf("unexpected blame for line 3 %v", b)
+	}
+	if tgit.BlameLine(ranges, 4) != nil {
+		t.Fatal("expected no blame past the end of the file")
+	}
+
+	ref := "dev"
+	if _, _, err := c.RepositoryFiles.GetFileBlame("group/project", &tgit.GetFileBlameOptions{FilePath: &filePath, Ref: &ref}); err == nil {
+		t.Fatal("expected an error for a file missing on the ref")
+	}
+
+	empty := ""
+	for _, opts := range []*tgit.GetFileBlameOptions{nil, {}, {FilePath: &empty}} {
+		if _, resp, err := c.RepositoryFiles.GetFileBlame("group/project", opts); err == nil || resp != nil {
+			t.Fatalf("expected a client side error for %v, got %v", opts, err)
+		}
+	}
+}
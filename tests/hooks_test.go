@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/liwenqiu/go-tgit"
+)
+
+type testMetrics struct {
+	mu        sync.Mutex
+	statuses  []int
+	endpoints []string
+	retries   int
+}
+
+func (m *testMetrics) ObserveRequest(method, endpoint string, status int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statuses = append(m.statuses, status)
+	m.endpoints = append(m.endpoints, method+" "+endpoint)
+}
+
+func (m *testMetrics) IncRetry(method, path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries++
+}
+
+func TestClient_MetricsHook(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"name":"master"}`)
+	}))
+	defer ts.Close()
+
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+
+	// Two clients sharing one HTTP client must not double count retries.
+	tgit.NewClient(hc, "other")
+	c, _ := tgit.NewClient(hc, "token")
+	c.SetBaseURL(ts.URL)
+
+	m := new(testMetrics)
+	c.AddHook(tgit.NewMetricsHook(m))
+
+	if _, _, err := c.Branches.GetBranch(1, "master"); err != nil {
+		t.Fatal(err)
+	}
+	if m.retries != 1 || len(m.statuses) != 1 || m.statuses[0] != http.StatusOK {
+		t.Fatalf("unexpected metrics: retries %d, statuses %v", m.retries, m.statuses)
+	}
+	if m.endpoints[0] != "GET projects/:id/repository/branches/:id" {
+		t.Fatalf("unexpected endpoint %s", m.endpoints[0])
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `null`)
+	}))
+	defer ts.Close()
+
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	c, _ := tgit.NewClient(hc, "token")
+	c.SetBaseURL(ts.URL)
+
+	m := new(testMetrics)
+	c.AddHook(tgit.NewMetricsHook(m))
+
+	filePath := "tags/blame"
+	for _, tc := range []struct {
+		call func() error
+		want string
+	}{
+		{func() error {
+			_, _, err := c.Commits.ListCommitStatuses("group/app", "0a1b2c3d", nil)
+			return err
+		}, "GET projects/:id/repository/commits/:id/statuses"},
+		{func() error {
+			_, _, err := c.RepositoryFiles.GetFileBlame(42, &tgit.GetFileBlameOptions{FilePath: &filePath})
+			return err
+		}, "GET projects/:id/repository/blame"},
+		{func() error {
+			_, _, err := c.Repositories.ListContributors("group/contributors")
+			return err
+		}, "GET projects/:id/repository/contributors"},
+		{func() error {
+			_, _, err := c.Reviews.SetReviewState(42, 7, &tgit.SetReviewStateOptions{})
+			return err
+		}, "PUT projects/:id/review/:id/reviewer/summary"},
+		{func() error {
+			_, _, err := c.Users.Get("first.last")
+			return err
+		}, "GET users/:id"},
+		{func() error {
+			_, _, err := c.Users.Get("")
+			return err
+		}, "GET user"},
+		{func() error {
+			req, err := c.NewRequest(http.MethodGet, "projects/42/custom", nil)
+			if err != nil {
+				return err
+			}
+			_, err = c.Do(req, nil)
+			return err
+		}, "GET other"},
+	} {
+		if err := tc.call(); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.endpoints[len(m.endpoints)-1]; got != tc.want {
+			t.Errorf("got endpoint %s, want %s", got, tc.want)
+		}
+	}
+}
+
+func TestClient_RetryHookInstalledOnce(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls%2 == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"name":"master"}`)
+	}))
+	defer ts.Close()
+
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	var logged int
+	hc.RequestLogHook = func(retryablehttp.Logger, *http.Request, int) { logged++ }
+
+	// The retry hook is chained onto the caller's hook once, sharing hc
+	// between clients does not report retries twice.
+	tgit.NewClient(hc, "token")
+	c, _ := tgit.NewClient(hc, "token")
+	c.SetBaseURL(ts.URL)
+
+	m := new(testMetrics)
+	c.AddHook(tgit.NewMetricsHook(m))
+
+	if _, _, err := c.Branches.GetBranch(1, "master"); err != nil {
+		t.Fatal(err)
+	}
+	if m.retries != 1 || logged != 2 {
+		t.Fatalf("unexpected retries %d and logged attempts %d", m.retries, logged)
+	}
+}
+
+func TestClient_AddHookConcurrently(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"master"}`)
+	}))
+	defer ts.Close()
+
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	c, _ := tgit.NewClient(hc, "token")
+	c.SetBaseURL(ts.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c.AddHook(tgit.NewMetricsHook(new(testMetrics)))
+		}()
+		go func() {
+			defer wg.Done()
+			c.Branches.GetBranch(1, "master")
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,275 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/liwenqiu/go-tgit"
+	"github.com/liwenqiu/go-tgit/tgittest"
+)
+
+func TestMergeRequestsService_ListMergeRequestChange(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+	s.AddMergeRequest(1, &tgit.MergeRequest{ID: 10, Iid: 1, State: "opened"})
+	s.AddMergeRequest(1, &tgit.MergeRequest{ID: 11, Iid: 2, State: "merged"})
+	s.AddMergeRequestChange(1, &tgit.MergeRequestChange{
+		MergeRequest: tgit.MergeRequest{ID: 10, Iid: 1, State: "opened"},
+		Files:        []*tgit.Diff{{OldPath: "a.go", NewPath: "a.go", Additions: 3}},
+	})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mrs, _, err := c.MergeRequests.ListMergeRequests("group/project", &tgit.ListMergeRequestsOptions{State: "opened"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mrs) != 1 || mrs[0].ID != 10 {
+		t.Fatalf("unexpected merge requests %v", mrs)
+	}
+
+	change, _, err := c.MergeRequests.ListMergeRequestChange("group/project", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change.Iid != 1 || len(change.Files) != 1 || change.Files[0].Additions != 3 {
+		t.Fatalf("unexpected change %v", change)
+	}
+}
+
+func TestMergeRequestsService_CompareMergeRequestDiffVersions(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+	for _, c := range []*tgit.Commit{
+		{ID: "ccc", ParentIDs: []string{"bbb"}},
+		{ID: "bbb", ParentIDs: []string{"aaa"}},
+		{ID: "aaa"},
+	} {
+		s.AddCommit(1, c)
+	}
+	s.AddCommitDiff(1, "bbb", []*tgit.Diff{{NewPath: "b.go"}})
+	s.AddCommitDiff(1, "ccc", []*tgit.Diff{{NewPath: "c.go"}})
+	s.AddMergeRequestDiffVersion(1, &tgit.MergeRequestDiffVersion{ID: 3, MergeRequestID: 10, HeadCommitSHA: "ccc"})
+	s.AddMergeRequestDiffVersion(1, &tgit.MergeRequestDiffVersion{ID: 2, MergeRequestID: 10, HeadCommitSHA: "bbb"})
+	s.AddMergeRequestDiffVersion(1, &tgit.MergeRequestDiffVersion{ID: 1, MergeRequestID: 10, HeadCommitSHA: "aaa"})
+	s.AddMergeRequestDiffVersion(1, &tgit.MergeRequestDiffVersion{ID: 4, MergeRequestID: 11, HeadCommitSHA: "ccc"})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vs, resp, err := c.MergeRequests.ListMergeRequestDiffVersions("group/project", 10, &tgit.ListMergeRequestDiffVersionsOptions{ListOptions: tgit.ListOptions{PerPage: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs) != 2 || vs[0].ID != 3 || resp.NextPage != 2 {
+		t.Fatalf("unexpected versions %v, next page %d", vs, resp.NextPage)
+	}
+
+	cmp, _, err := c.MergeRequests.CompareMergeRequestDiffVersions("group/project", 10, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmp.CommitsTotal != 2 || cmp.Commit.ID != "ccc" || len(cmp.Diffs) != 2 {
+		t.Fatalf("unexpected compare %v", cmp)
+	}
+
+	// Comparing a version with itself is an empty compare, not an error.
+	cmp, _, err = c.MergeRequests.CompareMergeRequestDiffVersions("group/project", 10, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.CompareSameRef || cmp.CommitsTotal != 0 {
+		t.Fatalf("unexpected compare %v", cmp)
+	}
+
+	if _, _, err := c.MergeRequests.CompareMergeRequestDiffVersions("group/project", 10, 1, 4); err == nil {
+		t.Fatal("expected an error for a version of another merge request")
+	}
+}
+
+func TestMergeRequestsService_ListAllMergeRequests(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.SetCurrentUser(&tgit.User{ID: 7, Username: "me"})
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/one"})
+	s.AddProject(&tgit.ProjectItem{ID: 2, PathWithNamespace: "group/two"})
+
+	day := func(d int) *tgit.Time {
+		return &tgit.Time{Time: time.Date(2021, 1, d, 0, 0, 0, 0, time.UTC)}
+	}
+	me := []*tgit.MergeRequestViewer{{ID: 7, Username: "me"}}
+	s.AddMergeRequest(1, &tgit.MergeRequest{ID: 1, State: "opened", Labels: []string{"bug", "p0"}, NecessaryReviewers: me, CreatedAt: day(3)})
+	s.AddMergeRequest(1, &tgit.MergeRequest{ID: 2, State: "opened", Labels: []string{"bug"}, NecessaryReviewers: me, CreatedAt: day(3)})
+	s.AddMergeRequest(2, &tgit.MergeRequest{ID: 3, State: "opened", Labels: []string{"bug", "p0"}, SuggestionReviewers: me, CreatedAt: day(5)})
+	s.AddMergeRequest(2, &tgit.MergeRequest{ID: 4, State: "opened", Labels: []string{"bug", "p0"}, NecessaryReviewers: me, CreatedAt: day(1)})
+	s.AddMergeRequest(2, &tgit.MergeRequest{ID: 5, State: "merged", Labels: []string{"bug", "p0"}, NecessaryReviewers: me, CreatedAt: day(3)})
+	s.AddMergeRequest(2, &tgit.MergeRequest{ID: 6, State: "opened", Labels: []string{"bug", "p0"}, CreatedAt: day(3)})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scope := tgit.InvolvingMe
+	reviewer := int64(7)
+	after := time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)
+	mrs, _, err := c.MergeRequests.ListAllMergeRequests(&tgit.ListAllMergeRequestsOptions{
+		ListMergeRequestsOptions: tgit.ListMergeRequestsOptions{
+			ListOptions:  tgit.ListOptions{PerPage: 50},
+			State:        "opened",
+			ReviewerID:   &reviewer,
+			Labels:       []string{"bug", "p0"},
+			CreatedAfter: &after,
+		},
+		Scope: &scope,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []int64
+	for _, mr := range mrs {
+		ids = append(ids, mr.ID)
+	}
+	if fmt.Sprint(ids) != "[1 3]" {
+		t.Fatalf("unexpected merge requests %v", ids)
+	}
+}
+
+func TestMergeRequestsService_EvaluateMergeReadiness(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, ApproverRule: 1})
+	s.AddBranch(1, &tgit.Branch{Name: "master", Protected: true, DevelopersCanMerge: true})
+	s.AddCommit(1, &tgit.Commit{ID: "abc"})
+	s.AddCommitStatus(1, &tgit.CommitStatus{ID: 1, SHA: "abc", Context: "ci", Status: "failed"})
+	s.AddCommitStatus(1, &tgit.CommitStatus{ID: 2, SHA: "abc", Context: "ci", Status: "success"})
+	s.AddCommitStatus(1, &tgit.CommitStatus{ID: 3, SHA: "abc", Context: "lint", Status: "pending"})
+	s.AddMergeRequest(1, &tgit.MergeRequest{
+		ID:           10,
+		State:        "opened",
+		MergeStatus:  "can_be_merged",
+		TargetBranch: "master",
+		SourceCommit: "abc",
+		NecessaryReviewers: []*tgit.MergeRequestViewer{
+			{Username: "alice", ReviewState: "approved"},
+			{Username: "bob", ReviewState: "reviewing"},
+		},
+	})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := c.MergeRequests.EvaluateMergeReadiness(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Ready() {
+		t.Fatal("expected the merge request to be blocked")
+	}
+
+	var reasons []tgit.BlockingReason
+	for _, b := range r.Blockers {
+		reasons = append(reasons, b.Reason)
+	}
+	want := []tgit.BlockingReason{tgit.BlockedNecessaryReviewer, tgit.BlockedStatusPending}
+	if fmt.Sprint(reasons) != fmt.Sprint(want) {
+		t.Fatalf("got blockers %v, want %v", r.Blockers, want)
+	}
+}
+
+func TestMergeRequestsService_EvaluateMergeReadinessRules(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		project    *tgit.ProjectItem
+		necessary  []*tgit.MergeRequestViewer
+		suggestion []*tgit.MergeRequestViewer
+		want       []tgit.BlockingReason
+	}{
+		{
+			name:    "protected branch without master access",
+			project: &tgit.ProjectItem{ID: 1},
+			want:    []tgit.BlockingReason{tgit.BlockedProtectedBranch},
+		},
+		{
+			name: "protected branch with group master access",
+			project: &tgit.ProjectItem{ID: 1, Permissions: &tgit.ProjectPermission{
+				ProjectAccess: tgit.Permission{AccessLevel: 30},
+				GroupAccess:   tgit.Permission{AccessLevel: 40},
+			}},
+		},
+		{
+			name: "more approvals required than reviewers",
+			project: &tgit.ProjectItem{ID: 1, ApproverRule: 3, Permissions: &tgit.ProjectPermission{
+				ProjectAccess: tgit.Permission{AccessLevel: 40},
+			}},
+			necessary: []*tgit.MergeRequestViewer{{ID: 1, Username: "alice", ReviewState: "approved"}},
+			suggestion: []*tgit.MergeRequestViewer{
+				{ID: 2, Username: "bob", ReviewState: "approved"},
+			},
+			want: []tgit.BlockingReason{tgit.BlockedApprovals},
+		},
+		{
+			name: "duplicate reviewers count once",
+			project: &tgit.ProjectItem{ID: 1, ApproverRule: 2, Permissions: &tgit.ProjectPermission{
+				ProjectAccess: tgit.Permission{AccessLevel: 40},
+			}},
+			necessary: []*tgit.MergeRequestViewer{
+				{ID: 1, Username: "alice", ReviewState: "approved"},
+				{ID: 1, Username: "alice", ReviewState: "approved"},
+			},
+			suggestion: []*tgit.MergeRequestViewer{{ID: 1, Username: "alice", ReviewState: "approved"}},
+			want:       []tgit.BlockingReason{tgit.BlockedApprovals},
+		},
+		{
+			name: "all reviewers must approve",
+			project: &tgit.ProjectItem{ID: 1, ApproverRule: -1, NecessaryApproverRule: -1, Permissions: &tgit.ProjectPermission{
+				ProjectAccess: tgit.Permission{AccessLevel: 40},
+			}},
+			necessary:  []*tgit.MergeRequestViewer{{ID: 1, Username: "alice", ReviewState: "approved"}},
+			suggestion: []*tgit.MergeRequestViewer{{ID: 2, Username: "bob", ReviewState: "reviewing"}},
+			want:       []tgit.BlockingReason{tgit.BlockedApprovals},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tgittest.NewServer()
+			defer s.Close()
+			s.AddProject(tt.project)
+			s.AddBranch(1, &tgit.Branch{Name: "master", Protected: true})
+			s.AddMergeRequest(1, &tgit.MergeRequest{
+				ID:                  10,
+				State:               "opened",
+				TargetBranch:        "master",
+				NecessaryReviewers:  tt.necessary,
+				SuggestionReviewers: tt.suggestion,
+			})
+
+			c, err := s.NewClient()
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := c.MergeRequests.EvaluateMergeReadiness(1, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var reasons []tgit.BlockingReason
+			for _, b := range r.Blockers {
+				reasons = append(reasons, b.Reason)
+			}
+			if fmt.Sprint(reasons) != fmt.Sprint(tt.want) {
+				t.Fatalf("got blockers %v, want %v", r.Blockers, tt.want)
+			}
+		})
+	}
+}
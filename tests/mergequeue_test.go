@@ -0,0 +1,374 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liwenqiu/go-tgit"
+	"github.com/liwenqiu/go-tgit/mergequeue"
+	"github.com/liwenqiu/go-tgit/tgittest"
+)
+
+func TestMergeQueue_Run(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+	s.AddBranch(1, &tgit.Branch{Name: "master"})
+	s.AddCommit(1, &tgit.Commit{ID: "a"})
+	s.AddCommit(1, &tgit.Commit{ID: "b"})
+	s.AddCommitStatus(1, &tgit.CommitStatus{ID: 1, SHA: "a", Context: "ci", Status: "success"})
+	s.AddCommitStatus(1, &tgit.CommitStatus{ID: 2, SHA: "b", Context: "ci", Status: "failed"})
+	s.AddMergeRequest(1, &tgit.MergeRequest{ID: 10, State: "opened", TargetBranch: "master", SourceCommit: "a"})
+	s.AddMergeRequest(1, &tgit.MergeRequest{ID: 11, State: "opened", TargetBranch: "master", SourceCommit: "b"})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu   sync.Mutex
+		done = make(map[int64]*mergequeue.Item)
+		all  = make(chan struct{})
+	)
+	store := mergequeue.NewMemoryStore()
+	q, err := mergequeue.New(context.Background(), c, store, &mergequeue.Options{
+		PollInterval: 10 * time.Millisecond,
+		OnDone: func(it *mergequeue.Item) {
+			mu.Lock()
+			defer mu.Unlock()
+			done[it.MergeRequestID] = it
+			if len(done) == 2 {
+				close(all)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []int64{10, 11} {
+		if _, err := q.Enqueue(context.Background(), "group/project", id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := q.Enqueue(context.Background(), "group/project", 10); err != mergequeue.ErrAlreadyQueued {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if items, _ := store.Load(context.Background()); len(items) != 2 {
+		t.Fatalf("expected 2 persisted items, got %d", len(items))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go q.Run(ctx)
+
+	select {
+	case <-all:
+	case <-ctx.Done():
+		t.Fatal("queue did not finish")
+	}
+
+	if done[10].State != mergequeue.StateMerged {
+		t.Fatalf("unexpected item %v: %s", done[10], done[10].Reason)
+	}
+	if done[11].State != mergequeue.StateFailed {
+		t.Fatalf("unexpected item %v", done[11])
+	}
+	if len(q.Items()) != 0 {
+		t.Fatalf("expected an empty queue, got %v", q.Items())
+	}
+}
+
+// runQueue enqueues the merge requests of group/project and runs the queue
+// until all of them are done.
+func runQueue(t *testing.T, s *tgittest.Server, opts mergequeue.Options, ids ...int64) map[int64]*mergequeue.Item {
+	t.Helper()
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu   sync.Mutex
+		done = make(map[int64]*mergequeue.Item)
+		all  = make(chan struct{})
+	)
+	opts.OnDone = func(it *mergequeue.Item) {
+		mu.Lock()
+		defer mu.Unlock()
+		done[it.MergeRequestID] = it
+		if len(done) == len(ids) {
+			close(all)
+		}
+	}
+	q, err := mergequeue.New(context.Background(), c, mergequeue.NewMemoryStore(), &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		if _, err := q.Enqueue(context.Background(), "group/project", id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go q.Run(ctx)
+
+	select {
+	case <-all:
+	case <-ctx.Done():
+		t.Fatal("queue did not finish")
+	}
+	return done
+}
+
+func TestMergeQueue_ProtectedBranch(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		permissions *tgit.ProjectPermission
+		want        mergequeue.State
+	}{
+		{name: "developer", permissions: &tgit.ProjectPermission{ProjectAccess: tgit.Permission{AccessLevel: 30}}, want: mergequeue.StateFailed},
+		{name: "master", permissions: &tgit.ProjectPermission{ProjectAccess: tgit.Permission{AccessLevel: 40}}, want: mergequeue.StateMerged},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tgittest.NewServer()
+			defer s.Close()
+			s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project", Permissions: tt.permissions})
+			s.AddBranch(1, &tgit.Branch{Name: "master", Protected: true})
+			s.AddMergeRequest(1, &tgit.MergeRequest{ID: 10, State: "opened", TargetBranch: "master"})
+
+			done := runQueue(t, s, mergequeue.Options{PollInterval: 10 * time.Millisecond}, 10)
+			if done[10].State != tt.want {
+				t.Fatalf("unexpected item %v: %s", done[10], done[10].Reason)
+			}
+			if tt.want == mergequeue.StateFailed && !strings.Contains(done[10].Reason, "protected") {
+				t.Fatalf("unexpected reason %q", done[10].Reason)
+			}
+		})
+	}
+}
+
+func TestMergeQueue_SourceBehindTarget(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		requireUpToDate bool
+		reason          string
+	}{
+		{name: "wait", reason: "timed out waiting for source branch is 1 commits behind master"},
+		{name: "fail", requireUpToDate: true, reason: "source branch is 1 commits behind master, rebase required"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tgittest.NewServer()
+			defer s.Close()
+			s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+			s.AddCommit(1, &tgit.Commit{ID: "target", ParentIDs: []string{"base"}})
+			s.AddCommit(1, &tgit.Commit{ID: "source", ParentIDs: []string{"base"}})
+			s.AddCommit(1, &tgit.Commit{ID: "base"})
+			s.AddBranch(1, &tgit.Branch{Name: "master", Commit: &tgit.Commit{ID: "target"}})
+			s.AddMergeRequest(1, &tgit.MergeRequest{ID: 10, State: "opened", TargetBranch: "master", SourceCommit: "source"})
+
+			done := runQueue(t, s, mergequeue.Options{
+				PollInterval:    10 * time.Millisecond,
+				StatusTimeout:   50 * time.Millisecond,
+				RequireUpToDate: tt.requireUpToDate,
+			}, 10)
+			if done[10].State != mergequeue.StateFailed || done[10].Reason != tt.reason {
+				t.Fatalf("unexpected item %v: %s", done[10], done[10].Reason)
+			}
+		})
+	}
+}
+
+func TestMergeQueue_MergeStatus(t *testing.T) {
+	for _, tt := range []struct {
+		status string
+		reason string
+	}{
+		{status: "unchecked", reason: "timed out waiting for merge status is unchecked"},
+		{status: "checking", reason: "timed out waiting for merge status is checking"},
+		{status: "cannot_be_merged", reason: "merge status is cannot_be_merged"},
+	} {
+		t.Run(tt.status, func(t *testing.T) {
+			s := tgittest.NewServer()
+			defer s.Close()
+			s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+			s.AddBranch(1, &tgit.Branch{Name: "master"})
+			s.AddMergeRequest(1, &tgit.MergeRequest{ID: 10, State: "opened", TargetBranch: "master", MergeStatus: tt.status})
+
+			done := runQueue(t, s, mergequeue.Options{
+				PollInterval:  10 * time.Millisecond,
+				StatusTimeout: 50 * time.Millisecond,
+			}, 10)
+			if done[10].State != mergequeue.StateFailed || done[10].Reason != tt.reason {
+				t.Fatalf("unexpected item %v: %s", done[10], done[10].Reason)
+			}
+		})
+	}
+}
+
+func TestMergeQueue_RetriesTransientErrors(t *testing.T) {
+	for _, code := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+		s := tgittest.NewServer()
+		s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+		s.AddBranch(1, &tgit.Branch{Name: "master"})
+		s.AddMergeRequest(1, &tgit.MergeRequest{ID: 10, State: "opened", TargetBranch: "master"})
+
+		c, err := s.NewClient()
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, err := mergequeue.New(context.Background(), c, mergequeue.NewMemoryStore(), &mergequeue.Options{
+			PollInterval: 10 * time.Millisecond,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := q.Enqueue(context.Background(), "group/project", 10); err != nil {
+			t.Fatal(err)
+		}
+
+		s.FailNext(2, code)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		go q.Run(ctx)
+		for len(q.Items()) != 0 && ctx.Err() == nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+		cancel()
+
+		mr, _, err := c.MergeRequests.GetMergeRequest("group/project", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mr.State != "merged" {
+			t.Fatalf("status %d: merge request is %s", code, mr.State)
+		}
+		s.Close()
+	}
+}
+
+func TestMergeQueue_InterruptedItemIsRequeued(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+	s.AddBranch(1, &tgit.Branch{Name: "master"})
+	s.AddCommit(1, &tgit.Commit{ID: "a"})
+	s.AddCommitStatus(1, &tgit.CommitStatus{ID: 1, SHA: "a", Context: "ci", Status: "pending"})
+	s.AddMergeRequest(1, &tgit.MergeRequest{ID: 10, State: "opened", TargetBranch: "master", SourceCommit: "a"})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := mergequeue.NewMemoryStore()
+	q, err := mergequeue.New(context.Background(), c, store, &mergequeue.Options{PollInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Enqueue(context.Background(), "group/project", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(stopped)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for q.Items()[0].State != mergequeue.StateProcessing {
+		if time.Now().After(deadline) {
+			t.Fatal("item was not processed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-stopped
+
+	if items, _ := store.Load(context.Background()); len(items) != 1 || items[0].State != mergequeue.StateQueued {
+		t.Fatalf("unexpected persisted items %v", items)
+	}
+	if err := q.Remove(context.Background(), "group/project", 10); err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Items()) != 0 {
+		t.Fatalf("expected an empty queue, got %v", q.Items())
+	}
+}
+
+// failingStore is a MemoryStore whose saves fail once fail is set.
+type failingStore struct {
+	*mergequeue.MemoryStore
+
+	mu   sync.Mutex
+	fail bool
+}
+
+var errStoreDown = errors.New("store is down")
+
+func (f *failingStore) Save(ctx context.Context, items []*mergequeue.Item) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.fail {
+		return errStoreDown
+	}
+	return f.MemoryStore.Save(ctx, items)
+}
+
+func TestMergeQueue_StopsOnStoreError(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+	s.AddBranch(1, &tgit.Branch{Name: "master"})
+	s.AddMergeRequest(1, &tgit.MergeRequest{ID: 10, State: "opened", TargetBranch: "master"})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &failingStore{MemoryStore: mergequeue.NewMemoryStore()}
+	q, err := mergequeue.New(context.Background(), c, store, &mergequeue.Options{
+		PollInterval: 10 * time.Millisecond,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Enqueue(context.Background(), "group/project", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	store.mu.Lock()
+	store.fail = true
+	store.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := q.Run(ctx); !errors.Is(err, errStoreDown) {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// The item was not taken up, so it is neither merged nor marked as being
+	// processed.
+	if items := q.Items(); len(items) != 1 || items[0].State != mergequeue.StateQueued {
+		t.Fatalf("unexpected items %v", items)
+	}
+	mr, _, err := c.MergeRequests.GetMergeRequest("group/project", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mr.State != "opened" {
+		t.Fatalf("merge request is %s", mr.State)
+	}
+}
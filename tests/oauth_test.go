@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/liwenqiu/go-tgit"
+)
+
+func TestOAuthConfig_AuthCodeURL(t *testing.T) {
+	cfg := &tgit.OAuthConfig{ClientID: "id", RedirectURL: "https://example.com/cb"}
+
+	u := cfg.AuthCodeURL("xyz")
+	if !strings.HasPrefix(u, "https://git.code.tencent.com/oauth/authorize?") ||
+		!strings.Contains(u, "client_id=id") || !strings.Contains(u, "state=xyz") ||
+		!strings.Contains(u, "redirect_uri=https%3A%2F%2Fexample.com%2Fcb") {
+		t.Fatalf("unexpected authorize URL %s", u)
+	}
+}
+
+func TestOAuthFlowClient_Refresh(t *testing.T) {
+	var refreshes int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/token":
+			r.ParseForm()
+			if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "refresh" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			refreshes++
+			fmt.Fprint(w, `{"access_token":"new","token_type":"bearer","expires_in":7200}`)
+		case "/api/v3/user":
+			if r.Header.Get("OAUTH-TOKEN") != "new" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"message":"401 Unauthorized"}`)
+				return
+			}
+			fmt.Fprint(w, `{"id":1,"username":"me"}`)
+		}
+	}))
+	defer ts.Close()
+
+	cfg := &tgit.OAuthConfig{ClientID: "id", ClientSecret: "secret", BaseURL: ts.URL}
+	store := tgit.NewMemoryTokenStore(&tgit.OAuthToken{AccessToken: "old", RefreshToken: "refresh"})
+
+	c, _ := tgit.NewOAuthFlowClient(retryablehttp.NewClient(), cfg, store)
+	c.SetBaseURL(ts.URL)
+
+	for i := 0; i < 2; i++ {
+		u, _, err := c.Users.Get("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Username != "me" {
+			t.Fatalf("unexpected user %v", u)
+		}
+	}
+
+	tok, _ := store.Token()
+	if refreshes != 1 || tok.AccessToken != "new" || tok.RefreshToken != "refresh" || tok.Expiry.IsZero() {
+		t.Fatalf("unexpected token %+v after %d refreshes", tok, refreshes)
+	}
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/liwenqiu/go-tgit"
+	"github.com/liwenqiu/go-tgit/tgittest"
+)
+
+func TestPathRules(t *testing.T) {
+	text := `# reviewers
+*.go 1 alice,bob
+/docs/ carol
+api/**/*.proto 2 dave,erin,frank
+`
+	rules, err := tgit.ParsePathRules(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 3 || rules[0].Count != 1 || rules[1].Count != 0 {
+		t.Fatalf("unexpected rules %v", rules)
+	}
+
+	for path, want := range map[string][]bool{
+		"main.go":                {true, false, false},
+		"pkg/util/strings.go":    {true, false, false},
+		"docs/index.md":          {false, true, false},
+		"src/docs/index.md":      {false, false, false},
+		"api/service.proto":      {false, false, true},
+		"api/v1/user/user.proto": {false, false, true},
+		"main.gox":               {false, false, false},
+	} {
+		for i, rule := range rules {
+			if got := rule.Matches(path); got != want[i] {
+				t.Errorf("rule %q on %s: got %v", rule.Pattern, path, got)
+			}
+		}
+	}
+
+	matches := rules.Required(tgit.ChangedPaths([]*tgit.Diff{
+		{OldPath: "old.go", NewPath: "docs/new.go", RenamedFile: true},
+	}))
+	if got := fmt.Sprint(matches.Users()); got != "[alice bob carol]" {
+		t.Fatalf("unexpected users %s", got)
+	}
+
+	owners := rules.Owners([]string{"docs/x.go", "README"})
+	if fmt.Sprint(owners["docs/x.go"]) != "[carol]" || owners["README"] != nil {
+		t.Fatalf("unexpected owners %v", owners)
+	}
+
+	again, err := tgit.ParsePathRules(rules.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != rules.String() {
+		t.Fatalf("serialization is not stable:\n%s\n%s", rules, again)
+	}
+
+	if _, err := tgit.ParsePathRules("*.go x alice"); err == nil {
+		t.Fatal("expected an error for an invalid count")
+	}
+}
+
+func TestPathRules_RoundTrip(t *testing.T) {
+	text := `# Go code needs one of the backend owners
+*.go 1 alice,bob
+
+# docs
+/docs/ carol
+# keep in sync with CODEOWNERS
+`
+	rules, err := tgit.ParsePathRules(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules.String() != text {
+		t.Fatalf("comments were not kept:\n%s", rules)
+	}
+
+	// The text form goes through the project settings unchanged.
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value := rules.String()
+	if _, _, err := c.Projects.EditProject("group/project", &tgit.EditProjectOptions{PathReviewerRules: &value}); err != nil {
+		t.Fatal(err)
+	}
+	p, _, err := c.Projects.GetProject("group/project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := p.ParsePathReviewerRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != text {
+		t.Fatalf("rules changed through the project settings:\n%s", again)
+	}
+}
+
+func TestPathRule_MatchesConcurrently(t *testing.T) {
+	rule := &tgit.PathRule{Pattern: "api/**/*.proto", Users: []string{"dave"}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !rule.Matches("api/v1/user.proto") {
+				t.Error("expected a match")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/liwenqiu/go-tgit"
+	"github.com/liwenqiu/go-tgit/tgittest"
+)
+
+// bodyRecorder records the method, path and body of every request it sends.
+type bodyRecorder struct {
+	requests []string
+}
+
+func (b *bodyRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	b.requests = append(b.requests, req.Method+" "+req.URL.EscapedPath()+" "+string(bytes.TrimSpace(body)))
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestProjectHooksService(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+	s.AddProjectHook(1, &tgit.ProjectHook{ID: 7, URL: "https://example.com/old", PushEvents: true})
+
+	rec := new(bodyRecorder)
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	hc.RetryMax = 0
+	hc.HTTPClient.Transport = rec
+	c, err := tgit.NewClient(hc, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetBaseURL(s.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	hookURL, token, yes, no := "https://example.com/hook", "hook-token", true, false
+	h, _, err := c.ProjectHooks.AddProjectHook("group/project", &tgit.AddProjectHookOptions{
+		URL:                 &hookURL,
+		PushEvents:          &no,
+		MergeRequestsEvents: &yes,
+		ReviewEvents:        &yes,
+		Token:               &token,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.ID != 8 || h.URL != hookURL || h.PushEvents || !h.MergeRequestsEvents || !h.ReviewEvents || h.NoteEvents {
+		t.Fatalf("unexpected hook %v", h)
+	}
+
+	h, _, err = c.ProjectHooks.EditProjectHook("group/project", h.ID, &tgit.EditProjectHookOptions{NoteEvents: &yes, ReviewEvents: &no})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !h.MergeRequestsEvents || !h.NoteEvents || h.ReviewEvents || h.URL != hookURL {
+		t.Fatalf("unexpected hook %v", h)
+	}
+
+	hooks, _, err := c.ProjectHooks.ListProjectHooks("group/project", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 2 || hooks[0].ID != 7 || hooks[1].ID != 8 {
+		t.Fatalf("unexpected hooks %v", hooks)
+	}
+
+	if _, err := c.ProjectHooks.DeleteProjectHook("group/project", 7); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.ProjectHooks.GetProjectHook("group/project", 7); err == nil {
+		t.Fatal("expected an error for a deleted hook")
+	}
+	h, _, err = c.ProjectHooks.GetProjectHook("group/project", 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.ProjectID != 1 {
+		t.Fatalf("unexpected hook %v", h)
+	}
+
+	want := []string{
+		`POST /api/v3/projects/group%2Fproject/hooks {"url":"https://example.com/hook","push_events":false,"merge_requests_events":true,"review_events":true,"token":"hook-token"}`,
+		`PUT /api/v3/projects/group%2Fproject/hooks/8 {"note_events":true,"review_events":false}`,
+		`GET /api/v3/projects/group%2Fproject/hooks `,
+		`DELETE /api/v3/projects/group%2Fproject/hooks/7 `,
+		`GET /api/v3/projects/group%2Fproject/hooks/7 `,
+		`GET /api/v3/projects/group%2Fproject/hooks/8 `,
+	}
+	if len(rec.requests) != len(want) {
+		t.Fatalf("got requests %q", rec.requests)
+	}
+	for i := range want {
+		if rec.requests[i] != want[i] {
+			t.Errorf("request %d: got %s, want %s", i, rec.requests[i], want[i])
+		}
+	}
+}
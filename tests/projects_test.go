@@ -1,24 +1,129 @@
 package tests
 
 import (
-	"fmt"
+	"errors"
+	"net/http"
 	"testing"
 
-	"github.com/hashicorp/go-retryablehttp"
 	"github.com/liwenqiu/go-tgit"
+	"github.com/liwenqiu/go-tgit/tgittest"
 )
 
 func TestProjectsService_ListProjects(t *testing.T) {
-	hc := retryablehttp.NewClient()
-	xc, _ := tgit.NewClient(hc, "IF9fJGOctqgsDw38SKq4")
-	repos, s, err := xc.Projects.ListProjects(&tgit.ListProjectsOptions{
-		ListOptions: tgit.ListOptions{Page: 1, PerPage: 30},
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, Name: "one", PathWithNamespace: "group/one"})
+	s.AddProject(&tgit.ProjectItem{ID: 2, Name: "two", PathWithNamespace: "group/two"})
+	s.AddProject(&tgit.ProjectItem{ID: 3, Name: "three", PathWithNamespace: "group/three"})
+
+	xc, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repos, resp, err := xc.Projects.ListProjects(&tgit.ListProjectsOptions{
+		ListOptions: tgit.ListOptions{Page: 1, PerPage: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 2 || repos[0].ID != 1 {
+		t.Fatalf("unexpected projects %v", repos)
+	}
+	if resp.TotalItems != 3 || resp.TotalPages != 2 || resp.NextPage != 2 {
+		t.Fatalf("unexpected pagination: total %d, pages %d, next %d", resp.TotalItems, resp.TotalPages, resp.NextPage)
+	}
+}
+
+func TestProjectsService_GetProject(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, Name: "one.go", PathWithNamespace: "group/one.go"})
+
+	xc, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, _, err := xc.Projects.GetProject("group/one.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != 1 {
+		t.Fatalf("unexpected project %v", p)
+	}
+
+	_, resp, err := xc.Projects.GetProject(2)
+	var e *tgit.ErrorResponse
+	if !errors.As(err, &e) || resp.StatusCode != http.StatusNotFound || e.Message != "{message: 404 Project Not Found}" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestProjectsService_Lifecycle(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.SetCurrentUser(&tgit.User{ID: 7, Username: "alice"})
+	s.AddNamespace(&tgit.ProjectNamespace{ID: 20, Name: "team", Path: "team"})
+
+	xc, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name, desc := "tool", "a tool"
+	p, _, err := xc.Projects.CreateProject(&tgit.CreateProjectOptions{Name: &name, Description: &desc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.PathWithNamespace != "alice/tool" || p.Description != "a tool" {
+		t.Fatalf("unexpected created project %v", p)
+	}
+	if _, _, err := xc.Projects.CreateProject(&tgit.CreateProjectOptions{Name: &name}); err == nil {
+		t.Fatal("expected an error for a duplicate path")
+	}
+
+	regex, template := "^feature/", "## Summary"
+	p, _, err = xc.Projects.EditProject("alice/tool", &tgit.EditProjectOptions{
+		BranchNameRegex:      &regex,
+		MergeRequestTemplate: &template,
 	})
 	if err != nil {
-		t.Fatalf(err.Error())
+		t.Fatal(err)
+	}
+	if p.BranchNameRegex != regex || p.MergeRequestTemplate != template || p.Description != "a tool" {
+		t.Fatalf("unexpected edited project %v", p)
+	}
+
+	ns := int64(20)
+	fork, _, err := xc.Projects.ForkProject(p.ID, &tgit.ForkProjectOptions{NamespaceID: &ns})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fork.PathWithNamespace != "team/tool" || fork.ID == p.ID || fork.BranchNameRegex != regex {
+		t.Fatalf("unexpected fork %v", fork)
+	}
+
+	if p, _, err = xc.Projects.ArchiveProject(p.ID); err != nil || !p.Archived {
+		t.Fatalf("unexpected archive result %v, %v", p, err)
+	}
+	if p, _, err = xc.Projects.UnarchiveProject(p.ID); err != nil || p.Archived {
+		t.Fatalf("unexpected unarchive result %v, %v", p, err)
+	}
+
+	if _, err := xc.Projects.TransferProject(p.ID, &tgit.TransferProjectOptions{NamespaceID: &ns}); err == nil {
+		t.Fatal("expected an error transferring onto the fork's path")
+	}
+	if _, err := xc.Projects.DeleteProject("team/tool"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := xc.Projects.TransferProject(p.ID, &tgit.TransferProjectOptions{NamespaceID: &ns}); err != nil {
+		t.Fatal(err)
+	}
+	if p, _, err = xc.Projects.GetProject("team/tool"); err != nil || p.Name != "tool" {
+		t.Fatalf("unexpected transferred project %v, %v", p, err)
 	}
-	fmt.Println(s.StatusCode)
-	for _, repo := range repos {
-		fmt.Println(repo.ID, repo.Name, repo.NameWithNamespace, repo.Description, repo.DefaultBranch, repo.HTTPSURLToRepo, repo.SSHURLToRepo, repo.WebURL)
+	if _, _, err := xc.Projects.GetProject("alice/tool"); err == nil {
+		t.Fatal("expected the old path to be gone")
 	}
 }
@@ -0,0 +1,231 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/liwenqiu/go-tgit"
+)
+
+func TestClient_RetryAfter(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("RateLimit-Limit", "600")
+		w.Header().Set("RateLimit-Remaining", "599")
+		w.Header().Set("RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Minute).Unix()))
+		fmt.Fprint(w, `{"id":1,"username":"tgit"}`)
+	}))
+	defer ts.Close()
+
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	hc.RetryWaitMin = time.Hour
+	c, _ := tgit.NewClient(hc, "token")
+	c.SetBaseURL(ts.URL)
+
+	u, resp, err := c.Users.Get("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || u.Username != "tgit" {
+		t.Fatalf("unexpected result after %d calls: %v", calls, u)
+	}
+	if resp.RateLimit != 600 || resp.RateLimitRemaining != 599 || resp.RateLimitReset.IsZero() {
+		t.Fatalf("unexpected rate limit values %d %d %v", resp.RateLimit, resp.RateLimitRemaining, resp.RateLimitReset)
+	}
+}
+
+func TestClient_RateLimitResetBackoff(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			// No Retry-After, only the reset time of the rate limit window.
+			w.Header().Set("RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Second).Unix()))
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"id":1,"username":"tgit"}`)
+	}))
+	defer ts.Close()
+
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	// The configured backoff would wait this long.
+	hc.RetryWaitMax = 10 * time.Second
+	hc.Backoff = func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+		return max
+	}
+	c, _ := tgit.NewClient(hc, "token")
+	c.SetBaseURL(ts.URL)
+
+	start := time.Now()
+	if _, _, err := c.Users.Get(""); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); calls != 2 || d > 5*time.Second {
+		t.Fatalf("expected a retry at the reset time, got %d calls after %v", calls, d)
+	}
+}
+
+func TestRateLimitResetBackoff_Clamped(t *testing.T) {
+	resp := func(reset time.Duration) *http.Response {
+		h := make(http.Header)
+		h.Set("RateLimit-Reset", fmt.Sprint(time.Now().Add(reset).Unix()))
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Header: h}
+	}
+
+	if d := tgit.RateLimitResetBackoff(time.Second, time.Minute, 1, resp(time.Hour)); d != time.Minute {
+		t.Fatalf("expected the wait to be clamped to the maximum, got %v", d)
+	}
+	if d := tgit.RateLimitResetBackoff(5*time.Second, time.Minute, 1, resp(2*time.Second)); d != 5*time.Second {
+		t.Fatalf("expected the wait to be raised to the minimum, got %v", d)
+	}
+}
+
+func TestClient_RetriesExhausted(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"message":"down for maintenance"}`)
+	}))
+	defer ts.Close()
+
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	hc.RetryMax = 1
+	c, _ := tgit.NewClient(hc, "token")
+	c.SetBaseURL(ts.URL)
+
+	// The status of the last attempt is reported, not an opaque error.
+	_, _, err := c.Users.Get("")
+	var e *tgit.ErrorResponse
+	if !errors.As(err, &e) || e.Response.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestClient_KeepsCustomBackoff(t *testing.T) {
+	var backoffs int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if backoffs == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"id":1}`)
+	}))
+	defer ts.Close()
+
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	hc.Backoff = func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+		backoffs++
+		return 0
+	}
+	c, _ := tgit.NewClient(hc, "token")
+	c.SetBaseURL(ts.URL)
+
+	if _, _, err := c.Users.Get(""); err != nil {
+		t.Fatal(err)
+	}
+	if backoffs != 1 {
+		t.Fatalf("expected the custom backoff to be used once, got %d", backoffs)
+	}
+}
+
+type countingLimiter struct {
+	waits int
+}
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	l.waits++
+	return nil
+}
+
+func TestClient_RateLimiterThrottlesRetries(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"id":1}`)
+	}))
+	defer ts.Close()
+
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	c, _ := tgit.NewClient(hc, "token")
+	c.SetBaseURL(ts.URL)
+
+	l := new(countingLimiter)
+	c.SetRateLimiter(l)
+
+	if _, _, err := c.Users.Get(""); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 || l.waits != 3 {
+		t.Fatalf("expected every attempt to wait on the limiter, got %d calls and %d waits", calls, l.waits)
+	}
+}
+
+func TestClient_SetRateLimiterConcurrently(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":1}`)
+	}))
+	defer ts.Close()
+
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	c, _ := tgit.NewClient(hc, "token")
+	c.SetBaseURL(ts.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c.SetRateLimiter(tgit.NewTokenBucket(1000, 10))
+		}()
+		go func() {
+			defer wg.Done()
+			c.Users.Get("")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTokenBucket_Wait(t *testing.T) {
+	b := tgit.NewTokenBucket(20, 1)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := b.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d < 90*time.Millisecond {
+		t.Fatalf("expected throttling, took %v", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := tgit.NewTokenBucket(0.001, 1).Wait(ctx); err != nil {
+		t.Fatalf("first token should be available, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/liwenqiu/go-tgit"
+	"github.com/liwenqiu/go-tgit/tgittest"
+)
+
+func TestRecorder_RecordReplay(t *testing.T) {
+	s := tgittest.NewServer()
+	s.Token = "secret-token"
+	s.AddProject(&tgit.ProjectItem{ID: 1, Name: "one"})
+	s.AddBranch(1, &tgit.Branch{Name: "master"})
+
+	golden := filepath.Join(t.TempDir(), "branches.json")
+
+	rec, err := tgittest.NewRecorder(golden, tgittest.ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	hc.HTTPClient.Transport = rec
+	c, _ := tgit.NewClient(hc, s.Token)
+	c.SetBaseURL(s.URL)
+
+	opts := &tgit.ListBranchesOptions{ListOptions: tgit.ListOptions{Page: 1, PerPage: 10}}
+	if _, _, err := c.Branches.ListBranches(1, opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	data, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), s.Token) {
+		t.Fatal("golden file contains the token")
+	}
+
+	rec, err = tgittest.NewRecorder(golden, tgittest.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hc = retryablehttp.NewClient()
+	hc.Logger = nil
+	hc.RetryMax = 0
+	hc.HTTPClient.Transport = rec
+	c, _ = tgit.NewClient(hc, "other-token")
+	c.SetBaseURL(s.URL)
+
+	b, resp, err := c.Branches.ListBranches(1, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 1 || b[0].Name != "master" || resp.TotalItems != 1 {
+		t.Fatalf("unexpected replay %v", b)
+	}
+
+	if _, _, err := c.Branches.GetBranch(1, "dev"); err == nil {
+		t.Fatal("expected an error for an unrecorded request")
+	}
+}
+
+func TestRecorder_RedactsSecrets(t *testing.T) {
+	const (
+		password     = "hunter2-password"
+		sessionToken = "session-private-token"
+		patValue     = "new-pat-value"
+		code         = "oauth-auth-code"
+		clientSecret = "oauth-client-secret"
+		accessToken  = "oauth-access-token"
+		refreshToken = "oauth-refresh-token"
+		queryToken   = "query-private-token"
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/session":
+			fmt.Fprintf(w, `{"id":1,"username":"alice","private_token":%q}`, sessionToken)
+		case "/api/v3/user/personal_access_tokens":
+			fmt.Fprintf(w, `{"id":2,"name":"ci","token":%q}`, patValue)
+		case "/oauth/token":
+			fmt.Fprintf(w, `{"access_token":%q,"refresh_token":%q,"expires_in":3600}`, accessToken, refreshToken)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer ts.Close()
+
+	golden := filepath.Join(t.TempDir(), "secrets.json")
+	rec, err := tgittest.NewRecorder(golden, tgittest.ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	hc.HTTPClient.Transport = rec
+
+	c, err := tgit.NewBasicAuthClient(hc, "alice", password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetBaseURL(ts.URL)
+	if _, err := c.Login(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	name := "ci"
+	if _, _, err := c.Users.CreatePersonalAccessToken(&tgit.CreatePersonalAccessTokenOptions{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &tgit.OAuthConfig{ClientID: "id", ClientSecret: clientSecret, BaseURL: ts.URL, HTTPClient: &http.Client{Transport: rec}}
+	if _, err := conf.Exchange(context.Background(), code); err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/v3/projects?private_token="+queryToken, nil)
+	resp, err := rec.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{password, sessionToken, patValue, code, clientSecret, accessToken, refreshToken, queryToken} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("golden file contains %s", secret)
+		}
+	}
+
+	// Replay matches on the redacted query, whatever token is sent.
+	rec, err = tgittest.NewRecorder(golden, tgittest.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ = http.NewRequest(http.MethodGet, ts.URL+"/api/v3/projects?private_token=another", nil)
+	if _, err := rec.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+}
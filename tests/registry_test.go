@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/liwenqiu/go-tgit"
+)
+
+func TestParseProjectURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://git.code.tencent.com/group/project":                    "group/project",
+		"https://git.code.tencent.com/group/sub/project.git":            "group/sub/project",
+		"https://git.code.tencent.com/group/project/merge_requests/12":  "group/project",
+		"https://git.code.tencent.com/group/project/blob/master/README": "group/project",
+		"git@git.code.tencent.com:group/project.git":                    "group/project",
+		"ssh://git@git.code.tencent.com:22/group/project.git":           "group/project",
+		"http://GIT.code.tencent.com/group/project.git":                 "group/project",
+		"https://git.code.tencent.com/group/tags":                       "group/tags",
+		"https://git.code.tencent.com/group/tags/tree/master":           "group/tags",
+		"https://git.code.tencent.com/group/sub/project/-/tags":         "group/sub/project",
+	} {
+		host, project, err := tgit.ParseProjectURL(in)
+		if err != nil {
+			t.Errorf("%s: %v", in, err)
+			continue
+		}
+		if host != "git.code.tencent.com" || project != want {
+			t.Errorf("%s: got %s %s", in, host, project)
+		}
+	}
+
+	for _, in := range []string{"https://git.code.tencent.com/", "https://git.code.tencent.com/group/-/tags"} {
+		if _, _, err := tgit.ParseProjectURL(in); err == nil {
+			t.Errorf("%s: expected an error for a URL without project", in)
+		}
+	}
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	r := tgit.NewRegistry(nil)
+
+	public, err := r.NewClient("https://git.code.tencent.com", tgit.StaticCredentials{Token: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal, err := r.NewClient("https://git.internal.example.com", tgit.StaticCredentials{Token: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, project, err := r.Resolve("git@git.internal.example.com:team/service.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != internal || project != "team/service" {
+		t.Fatalf("unexpected resolution %p %s", c, project)
+	}
+
+	if c, _, _ := r.Resolve("https://git.code.tencent.com/team/service"); c != public {
+		t.Fatal("expected the public client")
+	}
+
+	if _, _, err := r.Resolve("https://github.com/team/service"); !errors.Is(err, tgit.ErrNoClientForHost) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestRegistry_NewClientWithoutCredentials(t *testing.T) {
+	r := tgit.NewRegistry(nil)
+
+	if _, err := r.NewClient("https://git.code.tencent.com", nil); err == nil {
+		t.Fatal("expected an error for a nil credentials provider")
+	}
+	if _, ok := r.Client("git.code.tencent.com"); ok {
+		t.Fatal("the client must not be registered")
+	}
+}
+
+func TestRegistry_SharedHTTPClientConfiguredOnce(t *testing.T) {
+	hc := retryablehttp.NewClient()
+	hc.HTTPClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{}}
+	var backoffs int
+	hc.Backoff = func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+		backoffs++
+		return 0
+	}
+
+	r := tgit.NewRegistry(hc)
+	for _, host := range []string{"https://a.example.com", "https://b.example.com", "https://c.example.com"} {
+		if _, err := r.NewClient(host, tgit.StaticCredentials{Token: "t"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Clients built directly on the shared HTTP client must not change it
+	// either.
+	tgit.NewClient(hc, "t")
+
+	suites := hc.HTTPClient.Transport.(*http.Transport).TLSClientConfig.CipherSuites
+	if len(suites) != 1 {
+		t.Fatalf("expected one cipher suite, got %v", suites)
+	}
+	if hc.Backoff(0, time.Second, 1, nil); backoffs != 1 {
+		t.Fatalf("expected the configured backoff to be called once, got %d", backoffs)
+	}
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/liwenqiu/go-tgit"
+	"github.com/liwenqiu/go-tgit/tgittest"
+)
+
+func TestReviewsService_Lifecycle(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.SetCurrentUser(&tgit.User{ID: 1, Username: "me"})
+	s.AddUser(&tgit.User{ID: 1, Username: "me"})
+	s.AddUser(&tgit.User{ID: 2, Username: "alice"})
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := new(pathHook)
+	c.AddHook(h)
+
+	title, source, target := "Review range", "bbb", "aaa"
+	rv, _, err := c.Reviews.CreateReview("group/project", &tgit.CreateReviewOptions{
+		Title:        &title,
+		SourceCommit: &source,
+		TargetCommit: &target,
+		ReviewerIDs:  []int64{2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rv.ID != 1 || rv.State != "opened" || rv.Author.Username != "me" || len(rv.Reviewers) != 1 || rv.Reviewers[0].Username != "alice" {
+		t.Fatalf("unexpected review %v", rv)
+	}
+
+	necessary := true
+	vs, _, err := c.Reviews.AddReviewers("group/project", rv.ID, &tgit.AddReviewersOptions{ReviewerIDs: []int64{1, 2}, Necessary: &necessary})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs) != 2 || vs[1].ID != 1 || vs[1].Type != "necessary" {
+		t.Fatalf("unexpected reviewers %v", vs)
+	}
+
+	event, summary := tgit.ReviewApprove, "LGTM"
+	v, _, err := c.Reviews.SetReviewState("group/project", rv.ID, &tgit.SetReviewStateOptions{ReviewerEvent: &event, Summary: &summary})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.ID != 1 || v.ReviewState != "approved" {
+		t.Fatalf("unexpected reviewer %v", v)
+	}
+
+	if _, err := c.Reviews.RemoveReviewer("group/project", rv.ID, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	body, path, line := "nit", "main.go", 3
+	if _, _, err := c.Reviews.CreateReviewNote("group/project", rv.ID, &tgit.CreateReviewNoteOptions{Body: &body, Path: &path, Line: &line}); err != nil {
+		t.Fatal(err)
+	}
+	notes, _, err := c.Reviews.ListReviewNotes("group/project", rv.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 1 || notes[0].Path != "main.go" || notes[0].Line != 3 || notes[0].CommitID != "bbb" {
+		t.Fatalf("unexpected notes %v", notes)
+	}
+
+	closeEvent := "close"
+	rv, _, err = c.Reviews.UpdateReview("group/project", rv.ID, &tgit.UpdateReviewOptions{StateEvent: &closeEvent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rv.State != "closed" || len(rv.Reviewers) != 1 {
+		t.Fatalf("unexpected review %v", rv)
+	}
+
+	// A closed review can neither be closed again nor reviewed.
+	_, resp, err := c.Reviews.UpdateReview("group/project", rv.ID, &tgit.UpdateReviewOptions{StateEvent: &closeEvent})
+	if err == nil || resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %v", err)
+	}
+	_, resp, err = c.Reviews.SetReviewState("group/project", rv.ID, &tgit.SetReviewStateOptions{ReviewerEvent: &event})
+	if err == nil || resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %v", err)
+	}
+
+	reopen := "reopen"
+	rv, _, err = c.Reviews.UpdateReview("group/project", rv.ID, &tgit.UpdateReviewOptions{StateEvent: &reopen})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rv.State != "reopened" {
+		t.Fatalf("unexpected review %v", rv)
+	}
+
+	want := "[" +
+		"/api/v3/projects/group%2Fproject/review " +
+		"/api/v3/projects/group%2Fproject/review/1/reviewers " +
+		"/api/v3/projects/group%2Fproject/review/1/reviewer/summary " +
+		"/api/v3/projects/group%2Fproject/review/1/reviewers/2 " +
+		"/api/v3/projects/group%2Fproject/review/1/notes " +
+		"/api/v3/projects/group%2Fproject/review/1/notes " +
+		"/api/v3/projects/group%2Fproject/review/1 " +
+		"/api/v3/projects/group%2Fproject/review/1 " +
+		"/api/v3/projects/group%2Fproject/review/1/reviewer/summary " +
+		"/api/v3/projects/group%2Fproject/review/1" +
+		"]"
+	if got := fmt.Sprint(h.paths); got != want {
+		t.Fatalf("got paths %s, want %s", got, want)
+	}
+}
+
+func TestReviewsService_ListReviews(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/project"})
+	for i, state := range []string{"opened", "closed", "opened", "opened"} {
+		s.AddReview(1, &tgit.Review{ID: int64(i + 1), State: state, Author: &tgit.MergeRequestUser{ID: int64(i%2 + 1)}})
+	}
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := new(pathHook)
+	c.AddHook(h)
+
+	state, author := "opened", int64(1)
+	rvs, resp, err := c.Reviews.ListReviews("group/project", &tgit.ListReviewsOptions{
+		ListOptions: tgit.ListOptions{PerPage: 1},
+		State:       &state,
+		AuthorID:    &author,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rvs) != 1 || rvs[0].ID != 1 || resp.NextPage != 2 {
+		t.Fatalf("unexpected reviews %v, next page %d", rvs, resp.NextPage)
+	}
+	if want := "author_id=1&per_page=1&state=opened"; h.queries[0] != want {
+		t.Fatalf("got query %s, want %s", h.queries[0], want)
+	}
+
+	if _, _, err := c.Reviews.GetReview("group/project", 9); err == nil {
+		t.Fatal("expected an error for an unknown review")
+	}
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/liwenqiu/go-tgit"
+)
+
+func TestBasicAuthClient_Login(t *testing.T) {
+	var logins int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/session":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["login"] != "me" || body["password"] != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"message":"401 Unauthorized"}`)
+				return
+			}
+			logins++
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":1,"username":"me","private_token":"token"}`)
+		case "/api/v3/user":
+			if r.Header.Get("PRIVATE-TOKEN") != "token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"id":1,"username":"me"}`)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := tgit.NewBasicAuthClient(retryablehttp.NewClient(), "me", "pass")
+	c.SetBaseURL(ts.URL)
+
+	for i := 0; i < 2; i++ {
+		u, _, err := c.Users.Get("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Username != "me" {
+			t.Fatalf("unexpected user %v", u)
+		}
+	}
+	if logins != 1 {
+		t.Fatalf("expected one login, got %d", logins)
+	}
+
+	c, _ = tgit.NewBasicAuthClient(retryablehttp.NewClient(), "me", "wrong")
+	c.SetBaseURL(ts.URL)
+	if _, _, err := c.Users.Get(""); err == nil {
+		t.Fatal("expected login to fail")
+	}
+}
+
+func TestBasicAuthClient_ReloginOnExpiry(t *testing.T) {
+	var logins int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/session":
+			logins++
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id":1,"username":"me","private_token":"token-%d"}`, logins)
+		case "/api/v3/user":
+			// Only the token of the latest session is valid.
+			if r.Header.Get("PRIVATE-TOKEN") != fmt.Sprintf("token-%d", logins) {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"message":"401 Unauthorized"}`)
+				return
+			}
+			fmt.Fprint(w, `{"id":1,"username":"me"}`)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := tgit.NewBasicAuthClient(retryablehttp.NewClient(), "me", "pass")
+	c.SetBaseURL(ts.URL)
+
+	if _, _, err := c.Users.Get(""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Expire the session behind the client's back.
+	logins++
+
+	u, _, err := c.Users.Get("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Username != "me" || logins != 3 {
+		t.Fatalf("expected a second login, got %d logins and user %v", logins, u)
+	}
+
+	// A token set explicitly is not replaced by logging in.
+	c.SetToken("stale")
+	if _, _, err := c.Users.Get(""); err == nil || logins != 3 {
+		t.Fatalf("expected a 401 without login, got %v after %d logins", err, logins)
+	}
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/liwenqiu/go-tgit"
+	"github.com/liwenqiu/go-tgit/tgittest"
+)
+
+const testPublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKI0wjfzHSLzK6bk4Kv+HwONEXN0rpcVDNmwNoO7S6sO test@example"
+
+func TestPublicKeyFingerprint(t *testing.T) {
+	fp, err := tgit.PublicKeyFingerprint(testPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fp != "SHA256:I0BN2ucio/mLGeA36D+RPOkUZljoj/O57Rvuk2CeAIA" {
+		t.Fatalf("unexpected fingerprint %s", fp)
+	}
+
+	fp, err = tgit.PublicKeyFingerprintMD5(testPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fp != "bb:cb:a9:75:db:30:87:e0:77:b0:92:ff:17:fd:e5:e9" {
+		t.Fatalf("unexpected fingerprint %s", fp)
+	}
+}
+
+func TestValidatePublicKey(t *testing.T) {
+	for _, key := range []string{
+		"",
+		"ssh-ed25519",
+		"ssh-foo AAAAC3NzaC1lZDI1NTE5AAAAIKI0wjfzHSLzK6bk4Kv+HwONEXN0rpcVDNmwNoO7S6sO",
+		"ssh-rsa AAAAC3NzaC1lZDI1NTE5AAAAIKI0wjfzHSLzK6bk4Kv+HwONEXN0rpcVDNmwNoO7S6sO",
+		"ssh-ed25519 !!!",
+	} {
+		if err := tgit.ValidatePublicKey(key); !errors.Is(err, tgit.ErrInvalidPublicKey) {
+			t.Errorf("key %q: expected ErrInvalidPublicKey, got %v", key, err)
+		}
+	}
+}
+
+func TestDeployKeysService(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/one"})
+	s.AddProject(&tgit.ProjectItem{ID: 2, PathWithNamespace: "group/two"})
+
+	rec := new(bodyRecorder)
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	hc.RetryMax = 0
+	hc.HTTPClient.Transport = rec
+	c, err := tgit.NewClient(hc, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetBaseURL(s.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	title, key, canPush := "runner", testPublicKey, true
+	k, _, err := c.DeployKeys.AddDeployKey("group/one", &tgit.AddDeployKeyOptions{Title: &title, Key: &key, CanPush: &canPush})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.ID != 1 || !k.CanPush || k.Key != testPublicKey {
+		t.Fatalf("unexpected key %v", k)
+	}
+
+	if _, _, err := c.DeployKeys.EnableDeployKey("group/two", k.ID); err != nil {
+		t.Fatal(err)
+	}
+	keys, _, err := c.DeployKeys.ListDeployKeys("group/two", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].ID != k.ID || !keys[0].CanPush {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+
+	if _, _, err := c.DeployKeys.DisableDeployKey("group/two", k.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.DeployKeys.GetDeployKey("group/two", k.ID); err == nil {
+		t.Fatal("expected an error for a disabled key")
+	}
+	// Disabling only affects the one project.
+	if _, _, err := c.DeployKeys.GetDeployKey("group/one", k.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.DeployKeys.DeleteDeployKey("group/one", k.ID); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _, err := c.DeployKeys.ListDeployKeys("group/one", nil); err != nil || len(keys) != 0 {
+		t.Fatalf("unexpected keys %v, %v", keys, err)
+	}
+
+	want := []string{
+		`POST /api/v3/projects/group%2Fone/deploy_keys {"title":"runner","key":"` + testPublicKey + `","can_push":true}`,
+		`POST /api/v3/projects/group%2Ftwo/deploy_keys/1/enable `,
+		`GET /api/v3/projects/group%2Ftwo/deploy_keys `,
+		`DELETE /api/v3/projects/group%2Ftwo/deploy_keys/1/disable `,
+		`GET /api/v3/projects/group%2Ftwo/deploy_keys/1 `,
+		`GET /api/v3/projects/group%2Fone/deploy_keys/1 `,
+		`DELETE /api/v3/projects/group%2Fone/deploy_keys/1 `,
+		`GET /api/v3/projects/group%2Fone/deploy_keys `,
+	}
+	if fmt.Sprintf("%q", rec.requests) != fmt.Sprintf("%q", want) {
+		t.Fatalf("got requests\n%q\nwant\n%q", rec.requests, want)
+	}
+}
+
+func TestDeployKeysService_ValidatesKey(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddProject(&tgit.ProjectItem{ID: 1, PathWithNamespace: "group/one"})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := new(pathHook)
+	c.AddHook(h)
+
+	title, bad := "runner", "ssh-ed25519 !!!"
+	for _, opts := range []*tgit.AddDeployKeyOptions{nil, {Title: &title}, {Title: &title, Key: &bad}} {
+		if _, _, err := c.DeployKeys.AddDeployKey("group/one", opts); !errors.Is(err, tgit.ErrInvalidPublicKey) {
+			t.Errorf("expected ErrInvalidPublicKey for %v, got %v", opts, err)
+		}
+	}
+	if len(h.paths) != 0 {
+		t.Fatalf("invalid keys were sent: %v", h.paths)
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/liwenqiu/go-tgit"
+)
+
+func TestTime_UnmarshalJSON(t *testing.T) {
+	utc := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	local := time.Date(2021, 3, 4, 5, 6, 7, 0, tgit.ChinaLoc)
+
+	for in, want := range map[string]time.Time{
+		`"2021-03-04T05:06:07Z"`:       utc,
+		`"2021-03-04T05:06:07+0000"`:   utc,
+		`"2021-03-04T05:06:07.123Z"`:   utc.Add(123 * time.Millisecond),
+		`"2021-03-04T13:06:07+08:00"`:  utc,
+		`"2021-03-04T13:06:07.5+0800"`: utc.Add(500 * time.Millisecond),
+		`"2021-03-04 13:06:07 +0800"`:  utc,
+		`"2021-03-04T05:06:07"`:        local,
+		`"2021-03-04 05:06:07"`:        local,
+		`"2021-03-04"`:                 time.Date(2021, 3, 4, 0, 0, 0, 0, tgit.ChinaLoc),
+	} {
+		var got tgit.Time
+		if err := json.Unmarshal([]byte(in), &got); err != nil {
+			t.Errorf("%s: %v", in, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("%s: got %v, want %v", in, got.Time, want)
+		}
+	}
+
+	var mr tgit.MergeRequest
+	if err := json.Unmarshal([]byte(`{"created_at":"2021-03-04T05:06:07+0000","resolved_at":null}`), &mr); err != nil {
+		t.Fatal(err)
+	}
+	if !mr.CreatedAt.Equal(utc) || mr.ResolvedAt != nil {
+		t.Fatalf("unexpected merge request times %v %v", mr.CreatedAt, mr.ResolvedAt)
+	}
+
+	var bad tgit.Time
+	if err := json.Unmarshal([]byte(`"yesterday"`), &bad); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestClient_SetServerLocation(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":"a","committed_date":"2021-03-04 05:06:07","authored_date":"2021-03-04T05:06:07Z"}]`)
+	}))
+	defer ts.Close()
+
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	public, _ := tgit.NewClient(hc, "t")
+	public.SetBaseURL(ts.URL)
+	internal, _ := tgit.NewClient(hc, "t")
+	internal.SetBaseURL(ts.URL)
+	internal.SetServerLocation(time.UTC)
+
+	cs, _, err := public.Commits.ListCommits(1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2021, 3, 4, 5, 6, 7, 0, tgit.ChinaLoc); !cs[0].CommittedDate.Equal(want) {
+		t.Fatalf("got %v, want %v", cs[0].CommittedDate, want)
+	}
+
+	cs, _, err = internal.Commits.ListCommits(1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC); !cs[0].CommittedDate.Equal(want) {
+		t.Fatalf("got %v, want %v", cs[0].CommittedDate, want)
+	}
+	// Values with an offset are kept as sent.
+	if want := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC); !cs[0].AuthoredDate.Equal(want) {
+		t.Fatalf("got %v, want %v", cs[0].AuthoredDate, want)
+	}
+	if internal.ServerLocation() != time.UTC || public.ServerLocation() != tgit.ChinaLoc {
+		t.Fatal("unexpected server locations")
+	}
+}
+
+func TestClient_ServerLocationAtDecodeTime(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"a":{"created_at":"2021-03-04 05:06:07","resolved_at":null},"b":{"created_at":""}}`)
+	}))
+	defer ts.Close()
+
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	c, _ := tgit.NewClient(hc, "t")
+	c.SetBaseURL(ts.URL)
+	c.SetServerLocation(time.UTC)
+
+	// Values held in maps are decoded in the client location as well.
+	req, err := c.NewRequest(http.MethodGet, "merge_requests", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mrs map[string]tgit.MergeRequest
+	if _, err := c.Do(req, &mrs); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC); !mrs["a"].CreatedAt.Equal(want) || mrs["a"].ResolvedAt != nil {
+		t.Fatalf("unexpected times %v %v", mrs["a"].CreatedAt, mrs["a"].ResolvedAt)
+	}
+	if !mrs["b"].CreatedAt.IsZero() {
+		t.Fatalf("expected a zero time, got %v", mrs["b"].CreatedAt)
+	}
+}
@@ -2,21 +2,165 @@ package tests
 
 import (
 	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
-	"github.com/hashicorp/go-retryablehttp"
 	"github.com/liwenqiu/go-tgit"
+	"github.com/liwenqiu/go-tgit/tgittest"
 )
 
 func TestUsersService_List(t *testing.T) {
-	hc := retryablehttp.NewClient()
-	c, _ := tgit.NewClient(hc, "4n-Bvu7L9OI8dVuTTT92")
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.Token = "test-token"
+	s.SetCurrentUser(&tgit.User{ID: 1, Username: "me"})
+	s.AddUser(&tgit.User{ID: 2, Username: "other"})
 
-	r, s, err := c.Users.Get("")
+	c, err := s.NewClient()
 	if err != nil {
-		t.Fatalf(err.Error())
+		t.Fatal(err)
 	}
 
-	fmt.Println(r)
-	fmt.Println(s.StatusCode)
+	r, _, err := c.Users.Get("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Username != "me" {
+		t.Fatalf("unexpected user %v", r)
+	}
+
+	r, _, err = c.Users.Get("other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ID != 2 {
+		t.Fatalf("unexpected user %v", r)
+	}
+}
+
+// pathHook records the escaped path and query of every request.
+type pathHook struct {
+	paths   []string
+	queries []string
+}
+
+func (h *pathHook) BeforeRequest(req *http.Request) {
+	h.paths = append(h.paths, req.URL.EscapedPath())
+	h.queries = append(h.queries, req.URL.RawQuery)
+}
+
+func (h *pathHook) AfterResponse(*http.Request, *http.Response, error, time.Duration) {}
+
+func (h *pathHook) OnRetry(*http.Request, int) {}
+
+func TestUsersService_GetEscapesUsername(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.AddUser(&tgit.User{ID: 2, Username: "first.last"})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := new(pathHook)
+	c.AddHook(h)
+
+	u, _, err := c.Users.Get(" first.last ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != 2 {
+		t.Fatalf("unexpected user %v", u)
+	}
+	c.Users.Get("first/last")
+
+	want := "[/api/v3/users/first%2Elast /api/v3/users/first%2Flast]"
+	if got := fmt.Sprint(h.paths); got != want {
+		t.Fatalf("got paths %s, want %s", got, want)
+	}
+}
+
+func TestUsersService_ListUsers(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.SetCurrentUser(&tgit.User{ID: 1, Username: "me"})
+	s.AddUser(&tgit.User{ID: 2, Username: "alice", Email: "alice@example.com", State: "active"})
+	s.AddUser(&tgit.User{ID: 3, Username: "bob", Email: "bob@example.com", State: "blocked"})
+	s.AddEmail(&tgit.Email{ID: 1, Email: "me@example.com"})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := "blocked"
+	us, _, err := c.Users.ListUsers(&tgit.ListUsersOptions{State: &state})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(us) != 1 || us[0].Username != "bob" {
+		t.Fatalf("unexpected users %v", us)
+	}
+
+	email := "alice@example.com"
+	if us, _, err = c.Users.ListUsers(&tgit.ListUsersOptions{Email: &email}); err != nil || len(us) != 1 || us[0].ID != 2 {
+		t.Fatalf("unexpected users %v, %v", us, err)
+	}
+
+	u, _, err := c.Users.GetUserByID(3)
+	if err != nil || u.Username != "bob" {
+		t.Fatalf("unexpected user %v, %v", u, err)
+	}
+
+	es, _, err := c.Users.ListEmails()
+	if err != nil || len(es) != 1 || es[0].Email != "me@example.com" {
+		t.Fatalf("unexpected emails %v, %v", es, err)
+	}
+}
+
+func TestUsersService_Admin(t *testing.T) {
+	s := tgittest.NewServer()
+	defer s.Close()
+	s.SetCurrentUser(&tgit.User{ID: 1, Username: "root", IsAdmin: true})
+
+	c, err := s.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	email, username, name := "carol@example.com", "carol", "Carol"
+	u, _, err := c.Users.CreateUser(&tgit.CreateUserOptions{Email: &email, Username: &username, Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Username != "carol" || u.State != "active" {
+		t.Fatalf("unexpected created user %v", u)
+	}
+	if _, _, err := c.Users.CreateUser(&tgit.CreateUserOptions{Email: &email, Username: &username, Name: &name}); err == nil {
+		t.Fatal("expected an error for a duplicate username")
+	}
+
+	bio := "on call"
+	if u, _, err = c.Users.ModifyUser(u.ID, &tgit.ModifyUserOptions{Bio: &bio}); err != nil || u.Bio != bio || u.Name != name {
+		t.Fatalf("unexpected modified user %v, %v", u, err)
+	}
+
+	if _, err := c.Users.BlockUser(u.ID); err != nil {
+		t.Fatal(err)
+	}
+	if u, _, _ = c.Users.GetUserByID(u.ID); u.State != "blocked" {
+		t.Fatalf("expected a blocked user, got %v", u)
+	}
+	if _, err := c.Users.UnblockUser(u.ID); err != nil {
+		t.Fatal(err)
+	}
+	if u, _, _ = c.Users.GetUserByID(u.ID); u.State != "active" {
+		t.Fatalf("expected an active user, got %v", u)
+	}
+
+	s.SetCurrentUser(&tgit.User{ID: 2, Username: "dev"})
+	if _, err := c.Users.BlockUser(u.ID); err == nil {
+		t.Fatal("expected non-admins to be refused")
+	}
 }
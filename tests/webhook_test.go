@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/liwenqiu/go-tgit/webhook"
+)
+
+func TestWebhookHandler_Push(t *testing.T) {
+	h := webhook.NewHandler("secret")
+
+	var got *webhook.PushEvent
+	h.OnPush(func(e *webhook.PushEvent) error {
+		got = e
+		return nil
+	})
+
+	payload := `{"object_kind":"push","ref":"refs/heads/master","project_id":42,"commits":[{"id":"abc","author":{"name":"a"}}]}`
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+	r.Header.Set("X-Event", "Push Hook")
+	r.Header.Set("X-Token", "secret")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+	}
+	if got == nil || got.ProjectID != 42 || len(got.Commits) != 1 || got.Commits[0].Author.Name != "a" {
+		t.Fatalf("unexpected event %v", got)
+	}
+}
+
+func TestWebhookHandler_InvalidToken(t *testing.T) {
+	h := webhook.NewHandler("secret")
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"object_kind":"push"}`))
+	r.Header.Set("X-Token", "wrong")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+}
+
+func TestParseEvent_ObjectKind(t *testing.T) {
+	e, err := webhook.ParseEvent("", []byte(`{"object_kind":"merge_request","object_attributes":{"iid":7,"action":"open"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mr, ok := e.(*webhook.MergeRequestEvent)
+	if !ok {
+		t.Fatalf("unexpected event type %T", e)
+	}
+	if mr.ObjectAttributes.Iid != 7 || mr.ObjectAttributes.Action != "open" {
+		t.Fatalf("unexpected event %v", mr)
+	}
+}
+
+func TestWebhookHandler_UnknownEvent(t *testing.T) {
+	h := webhook.NewHandler("")
+
+	serve := func() int {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"object_kind":"pipeline"}`))
+		r.Header.Set("X-Event", "Pipeline Hook")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := serve(); code != http.StatusNoContent {
+		t.Fatalf("unknown events should be acknowledged, got %d", code)
+	}
+
+	var got webhook.EventType
+	h.OnUnknown(func(eventType webhook.EventType, payload []byte) error {
+		got = eventType
+		return nil
+	})
+	if code := serve(); code != http.StatusNoContent || got != "Pipeline Hook" {
+		t.Fatalf("unexpected status %d and event type %q", code, got)
+	}
+}
+
+func TestWebhookHandler_PayloadTooLarge(t *testing.T) {
+	h := webhook.NewHandler("secret")
+	h.SetMaxPayloadSize(16)
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"object_kind":"push","ref":"refs/heads/master"}`))
+	r.Header.Set("X-Event", "Push Hook")
+	r.Header.Set("X-Token", "secret")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+}
@@ -1,6 +1,7 @@
 package tgit
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
@@ -8,11 +9,19 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"weak"
 
+	jsonv2 "github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+	jsonv1 "github.com/go-json-experiment/json/v1"
 	"github.com/google/go-querystring/query"
 	"github.com/hashicorp/go-retryablehttp"
 )
@@ -27,28 +36,38 @@ var (
 	ChinaLoc = time.FixedZone("Asia/Shanghai", int((8 * time.Hour).Seconds()))
 )
 
-type authType int
-
-const (
-	basicAuth authType = iota
-	oAuthToken
-	privateToken
-)
-
 type Client struct {
 	// HTTP client used to communicate with the API.
 	client *retryablehttp.Client
 
 	baseURL *url.URL
 
-	// Token types used to make authenticated API calls.
-	authType authType
+	// Guards credentials, which change when a basic auth client logs in or
+	// the credentials are replaced.
+	authMu sync.RWMutex
+
+	// Provider consulted for the credentials of every request, nil until a
+	// basic auth client has logged in.
+	credentials CredentialsProvider
 
 	// Username and password used for basic authentication.
 	username, password string
 
-	// Token used to make authenticated API calls.
-	token string
+	// Limiter used to throttle requests, nil means no throttling.
+	limiterMu sync.RWMutex
+	limiter   RateLimiter
+
+	// Cache used for conditional GET requests, nil means no caching.
+	cacheMu sync.RWMutex
+	cache   Cache
+
+	// Location of timestamps sent without an offset, nil means
+	// ChinaLoc.
+	location atomic.Pointer[time.Location]
+
+	// Hooks notified about every request, see AddHook.
+	hooksMu sync.RWMutex
+	hooks   []RequestHook
 
 	// User agent used when communicating with the TGit API.
 	UserAgent string
@@ -56,11 +75,14 @@ type Client struct {
 	// Services used for talking to different parts of the TGit API.
 	Branches        *BranchesService
 	Commits         *CommitsService
+	DeployKeys      *DeployKeysService
 	Repositories    *RepositoriesService
 	RepositoryFiles *RepositoryFilesService
 	Tags            *TagsService
 	Projects        *ProjectsService
+	ProjectHooks    *ProjectHooksService
 	MergeRequests   *MergeRequestsService
+	Reviews         *ReviewsService
 	Users           *UsersService
 }
 
@@ -75,8 +97,7 @@ func NewClient(hc *retryablehttp.Client, token string) (*Client, error) {
 		return nil, err
 	}
 
-	client.authType = privateToken
-	client.token = token
+	client.credentials = StaticCredentials{Token: token}
 	return client, nil
 }
 
@@ -86,7 +107,6 @@ func NewBasicAuthClient(hc *retryablehttp.Client, username, password string) (*C
 		return nil, err
 	}
 
-	client.authType = basicAuth
 	client.username = username
 	client.password = password
 
@@ -99,26 +119,75 @@ func NewOAuthClient(hc *retryablehttp.Client, token string) (*Client, error) {
 		return nil, err
 	}
 
-	client.authType = oAuthToken
-	client.token = token
+	client.credentials = StaticCredentials{Token: token, OAuth: true}
 	return client, nil
 }
 
 func newClient(hc *retryablehttp.Client) (*Client, error) {
-	c := &Client{UserAgent: userAgent}
+	configureHTTPClient(hc)
+	return newSharedClient(hc)
+}
+
+// configuredClients holds the HTTP clients configureHTTPClient has prepared.
+// The keys are weak pointers so the set does not keep the clients alive.
+var configuredClients sync.Map
+
+// configureHTTPClient prepares hc for the TGit API: TLS settings, the error
+// handler, the rate limit aware backoff and the retry hooks. hc is prepared once, so it can be
+// shared between clients, hooks set on it afterwards replace the ones
+// installed here.
+func configureHTTPClient(hc *retryablehttp.Client) {
+	if hc == nil {
+		return
+	}
+	key := weak.Make(hc)
+	if _, loaded := configuredClients.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+	runtime.AddCleanup(hc, func(key weak.Pointer[retryablehttp.Client]) {
+		configuredClients.Delete(key)
+	}, key)
 
 	setTlsConfig(hc)
+	if hc.ErrorHandler == nil {
+		hc.ErrorHandler = lastResponseErrorHandler
+	}
+	installBackoff(hc)
+	installRetryLimiter(hc)
+	installRetryHook(hc)
+}
+
+// lastResponseErrorHandler hands the last response to the client once the
+// retries are exhausted, so a failing status surfaces as an ErrorResponse
+// through CheckResponse instead of an opaque error.
+func lastResponseErrorHandler(resp *http.Response, err error, numTries int) (*http.Response, error) {
+	if err != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// newSharedClient returns a client using hc as it is, hc must have been
+// passed through configureHTTPClient.
+func newSharedClient(hc *retryablehttp.Client) (*Client, error) {
+	c := &Client{UserAgent: userAgent}
 	c.client = hc
 
-	c.setBaseURL(defaultBaseURL)
+	c.SetBaseURL(defaultBaseURL)
 
 	c.Branches = &BranchesService{client: c}
 	c.Commits = &CommitsService{client: c}
+	c.DeployKeys = &DeployKeysService{client: c}
 	c.Repositories = &RepositoriesService{client: c}
 	c.RepositoryFiles = &RepositoryFilesService{client: c}
 	c.Tags = &TagsService{client: c}
 	c.Projects = &ProjectsService{client: c}
+	c.ProjectHooks = &ProjectHooksService{client: c}
 	c.MergeRequests = &MergeRequestsService{client: c}
+	c.Reviews = &ReviewsService{client: c}
 	c.Users = &UsersService{client: c}
 
 	return c, nil
@@ -144,12 +213,18 @@ func setTlsConfig(hc *retryablehttp.Client) {
 		return
 	}
 
-	if hc.HTTPClient.Transport.(*http.Transport).TLSClientConfig != nil {
-		hc.HTTPClient.Transport.(*http.Transport).TLSClientConfig.CipherSuites =
-			append(hc.HTTPClient.Transport.(*http.Transport).TLSClientConfig.CipherSuites, tls.TLS_RSA_WITH_RC4_128_SHA)
+	// Custom round trippers, e.g. recorders or proxies, manage TLS on their own.
+	t, ok := hc.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		return
+	}
 
+	if t.TLSClientConfig != nil {
+		if !slices.Contains(t.TLSClientConfig.CipherSuites, tls.TLS_RSA_WITH_RC4_128_SHA) {
+			t.TLSClientConfig.CipherSuites = append(t.TLSClientConfig.CipherSuites, tls.TLS_RSA_WITH_RC4_128_SHA)
+		}
 	} else {
-		hc.HTTPClient.Transport.(*http.Transport).TLSClientConfig = &tls.Config{
+		t.TLSClientConfig = &tls.Config{
 			MinVersion:   tls.VersionTLS12,
 			MaxVersion:   tls.VersionTLS13,
 			CipherSuites: []uint16{tls.TLS_RSA_WITH_RC4_128_SHA},
@@ -163,8 +238,8 @@ func (c *Client) BaseURL() *url.URL {
 	return &u
 }
 
-// setBaseURL sets the base URL for API requests to a custom encoding.
-func (c *Client) setBaseURL(urlStr string) error {
+// SetBaseURL sets the base URL for API requests to a custom endpoint.
+func (c *Client) SetBaseURL(urlStr string) error {
 	// Make sure the given URL end with a slash
 	if !strings.HasSuffix(urlStr, "/") {
 		urlStr += "/"
@@ -185,6 +260,34 @@ func (c *Client) setBaseURL(urlStr string) error {
 	return nil
 }
 
+// route is an API path together with the fmt pattern it was built from,
+// the pattern labels the request in metrics, see Endpoint.
+type route struct {
+	pattern string
+	path    string
+}
+
+// newRoute formats pattern with args like fmt.Sprintf.
+func newRoute(pattern string, args ...interface{}) route {
+	return route{pattern: pattern, path: fmt.Sprintf(pattern, args...)}
+}
+
+type routeContextKey struct{}
+
+// newRequest is NewRequest for a route of the API.
+func (c *Client) newRequest(method string, r route, opt interface{}) (*retryablehttp.Request, error) {
+	return c.newRequestWithContext(context.Background(), method, r, opt)
+}
+
+// newRequestWithContext is newRequest for a request bound to ctx.
+func (c *Client) newRequestWithContext(ctx context.Context, method string, r route, opt interface{}) (*retryablehttp.Request, error) {
+	req, err := c.NewRequest(method, r.path, opt)
+	if err != nil {
+		return nil, err
+	}
+	return req.WithContext(context.WithValue(ctx, routeContextKey{}, r.pattern)), nil
+}
+
 func (c *Client) NewRequest(method, path string, opt interface{}) (*retryablehttp.Request, error) {
 	u := *c.baseURL
 	unescaped, err := url.PathUnescape(path)
@@ -244,12 +347,19 @@ type Response struct {
 	CurrentPage  int
 	NextPage     int
 	PreviousPage int
+
+	// Rate limit values reported by the server, zero when absent.
+	RateLimit          int
+	RateLimitRemaining int
+	RateLimitReset     time.Time
+	RetryAfter         time.Duration
 }
 
 // newResponse creates a new Response for the provided http.Response.
 func newResponse(r *http.Response) *Response {
 	response := &Response{Response: r}
 	response.populatePageValues()
+	response.populateRateLimitValues()
 	return response
 }
 
@@ -286,33 +396,70 @@ func (r *Response) populatePageValues() {
 }
 
 func (c *Client) Do(req *retryablehttp.Request, v interface{}) (*Response, error) {
+	creds, token, err := c.setAuthHeaders(req)
+	if err != nil {
+		return nil, err
+	}
 
-	switch c.authType {
-	case oAuthToken:
-		req.Header.Set("OAUTH-TOKEN", c.token)
-	case privateToken:
-		// https://code.tencent.com/help/api/prepare#authentication
-		req.Header.Set("PRIVATE-TOKEN", c.token)
+	if l := c.rateLimiter(); l != nil {
+		if err := l.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+		req = req.WithContext(context.WithValue(req.Context(), limiterContextKey{}, l))
+	}
+
+	cache := c.responseCache()
+	cacheable := cache != nil && req.Method == http.MethodGet
+
+	var cached *CacheEntry
+	if cacheable {
+		cached = setConditionalHeaders(cache, req)
 	}
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
 
+	// An expired OAuth token or session is renewed and the request sent once
+	// more.
+	if r, ok := creds.(refresher); ok && resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+
+		fresh, err := r.refresh(req.Context(), token)
+		if err != nil {
+			return nil, err
+		}
+		setTokenHeader(req, fresh)
+
+		resp, err = c.do(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	defer resp.Body.Close()
 
+	// A 304 has no body to decode unless it can be served from the cache.
+	hasBody := resp.StatusCode != http.StatusNotModified
+	if cacheable {
+		hasBody, err = applyCache(cache, req, resp, cached)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	response := newResponse(resp)
 
 	err = CheckResponse(resp)
 	if err != nil {
 		return response, err
 	}
-	if v != nil {
+	if v != nil && hasBody {
 		if w, ok := v.(io.Writer); ok {
 			_, err = io.Copy(w, resp.Body)
 		} else {
-			err = json.NewDecoder(resp.Body).Decode(v)
+			err = c.decodeJSON(resp.Body, v)
 		}
 	}
 
@@ -325,10 +472,12 @@ func parseID(id interface{}) (string, error) {
 	switch v := id.(type) {
 	case int:
 		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
 	case string:
 		return v, nil
 	default:
-		return "", fmt.Errorf("invalid ID type %#v, the ID must be an int or a string", id)
+		return "", fmt.Errorf("invalid ID type %#v, the ID must be an int, an int64 or a string", id)
 	}
 }
 
@@ -408,17 +557,95 @@ func (t *Time) AsTime() *time.Time {
 	return &date
 }
 
-func (t *Time) UnmarshalJSON(data []byte) (err error) {
+// timeLayouts are the formats with an explicit offset TGit emits, the
+// fractional seconds are optional.
+var timeLayouts = []string{
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999 -0700",
+}
+
+// localTimeLayouts carry no offset and are interpreted in the server
+// location, ChinaLoc unless the client decoding them was given another one.
+var localTimeLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// ParseTime parses the timestamp formats used by TGit. Offsets are kept as
+// sent, values without one are read in ChinaLoc.
+func ParseTime(s string) (time.Time, error) {
+	return ParseTimeIn(s, ChinaLoc)
+}
+
+// ParseTimeIn is like ParseTime but reads values without an offset in loc.
+func ParseTimeIn(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as a TGit timestamp", s)
+}
+
+// UnmarshalJSON reads values without an offset in ChinaLoc, responses decoded
+// by a client use its server location instead.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	return t.unmarshalJSONIn(data, ChinaLoc)
+}
+
+func (t *Time) unmarshalJSONIn(data []byte, loc *time.Location) (err error) {
 	s := string(data)
 	if s == "null" || s == `""` {
 		return nil
 	}
 
-	s = strings.ReplaceAll(s, "+0000", "+08:00")
-	t.Time, err = time.Parse(`"`+time.RFC3339+`"`, s)
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	t.Time, err = ParseTimeIn(s, loc)
 	return err
 }
 
+// decodeJSON decodes r into v like encoding/json, except that timestamps
+// sent without an offset are read in the server location of the client.
+func (c *Client) decodeJSON(r io.Reader, v interface{}) error {
+	loc := c.ServerLocation()
+	return jsonv2.UnmarshalRead(r, v, jsonv1.DefaultOptionsV1(), jsonv2.WithUnmarshalers(
+		jsonv2.UnmarshalFromFunc(func(dec *jsontext.Decoder, t *Time) error {
+			data, err := dec.ReadValue()
+			if err != nil {
+				return err
+			}
+			return t.unmarshalJSONIn(data, loc)
+		}),
+	))
+}
+
+// SetServerLocation sets the time zone in which the client reads timestamps
+// sent without an offset, ChinaLoc by default. Pass nil to go back to
+// ChinaLoc.
+func (c *Client) SetServerLocation(loc *time.Location) {
+	c.location.Store(loc)
+}
+
+// ServerLocation returns the time zone in which the client reads timestamps
+// sent without an offset.
+func (c *Client) ServerLocation() *time.Location {
+	if loc := c.location.Load(); loc != nil {
+		return loc
+	}
+	return ChinaLoc
+}
+
 func (t *Time) MarshalJSON() ([]byte, error) {
 	return t.Time.MarshalJSON()
 }
@@ -0,0 +1,97 @@
+package tgittest
+
+import (
+	"net/http"
+
+	tgit "github.com/liwenqiu/go-tgit"
+)
+
+// resolveRef returns the commit a branch, tag or (short) SHA points to.
+func resolveRef(p *project, ref string) *tgit.Commit {
+	for _, b := range p.branches {
+		if b.Name == ref && b.Commit != nil {
+			return findCommit(p, b.Commit.ID)
+		}
+	}
+	for _, t := range p.tags {
+		if t.Name == ref && t.Commit != nil {
+			return findCommit(p, t.Commit.ID)
+		}
+	}
+	return findCommit(p, ref)
+}
+
+func findCommit(p *project, sha string) *tgit.Commit {
+	for _, c := range p.commits {
+		if c.ID == sha || (c.ShortID != "" && c.ShortID == sha) {
+			return c
+		}
+	}
+	return nil
+}
+
+// ancestors returns the IDs of c and every seeded commit reachable from it
+// through its parents.
+func ancestors(p *project, c *tgit.Commit) map[string]bool {
+	seen := make(map[string]bool)
+	queue := []*tgit.Commit{c}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		if c == nil || seen[c.ID] {
+			continue
+		}
+		seen[c.ID] = true
+		for _, id := range c.ParentIDs {
+			queue = append(queue, findCommit(p, id))
+		}
+	}
+	return seen
+}
+
+// serveCompare answers with the commits reachable from "to" but not from
+// "from", newest first, and the diffs seeded for them.
+func (s *Server) serveCompare(w http.ResponseWriter, r *http.Request, p *project) {
+	q := r.URL.Query()
+	from, to := resolveRef(p, q.Get("from")), resolveRef(p, q.Get("to"))
+	if from == nil || to == nil {
+		writeError(w, http.StatusNotFound, "404 Ref Not Found")
+		return
+	}
+
+	base := ancestors(p, from)
+	head := ancestors(p, to)
+	cmp := &tgit.Compare{Commit: to, CompareSameRef: from.ID == to.ID}
+	// p.commits is ordered newest first, like ListCommits.
+	for _, c := range p.commits {
+		if head[c.ID] && !base[c.ID] {
+			cmp.Commits = append(cmp.Commits, c)
+			cmp.Diffs = append(cmp.Diffs, p.diffs[c.ID]...)
+		}
+	}
+	cmp.CommitsTotal = len(cmp.Commits)
+	cmp.FilesTotal = len(cmp.Diffs)
+	writeJSON(w, http.StatusOK, cmp)
+}
+
+// serveContributors aggregates the seeded commits by author, counting the
+// lines of their seeded diffs.
+func (s *Server) serveContributors(w http.ResponseWriter, r *http.Request, p *project) {
+	var contributors []*tgit.Contributor
+	byAuthor := make(map[string]*tgit.Contributor)
+	for _, c := range p.commits {
+		key := c.AuthorEmail + "\x00" + c.AuthorName
+		ct, ok := byAuthor[key]
+		if !ok {
+			ct = &tgit.Contributor{Name: c.AuthorName, Email: c.AuthorEmail}
+			byAuthor[key] = ct
+			contributors = append(contributors, ct)
+		}
+		ct.Commits++
+		for _, d := range p.diffs[c.ID] {
+			ct.Additions += d.Additions
+			ct.Deletions += d.Deletions
+		}
+	}
+	writeJSON(w, http.StatusOK, contributors)
+}
@@ -0,0 +1,92 @@
+package tgittest
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	tgit "github.com/liwenqiu/go-tgit"
+)
+
+// AddDeployKey seeds a deploy key enabled on the project.
+func (s *Server) AddDeployKey(pid int64, k *tgit.DeployKey) {
+	s.withProject(pid, func(p *project) {
+		s.deployKeys = append(s.deployKeys, k)
+		p.deployKeys = append(p.deployKeys, k.ID)
+	})
+}
+
+func (s *Server) findDeployKey(id string) *tgit.DeployKey {
+	for _, k := range s.deployKeys {
+		if strconv.FormatInt(k.ID, 10) == id {
+			return k
+		}
+	}
+	return nil
+}
+
+// serveDeployKeys answers the routes below projects/:id/deploy_keys. Keys
+// are shared between projects: enabling adds an existing key to the project,
+// disabling and deleting remove it from the project only.
+func (s *Server) serveDeployKeys(w http.ResponseWriter, r *http.Request, p *project, segs []string) {
+	if len(segs) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			var keys []*tgit.DeployKey
+			for _, id := range p.deployKeys {
+				keys = append(keys, s.findDeployKey(strconv.FormatInt(id, 10)))
+			}
+			writePage(w, r, keys)
+		case http.MethodPost:
+			s.addDeployKey(w, r, p)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+		}
+		return
+	}
+
+	k := s.findDeployKey(segs[0])
+	if k == nil {
+		writeError(w, http.StatusNotFound, "404 Deploy Key Not Found")
+		return
+	}
+	i := slices.Index(p.deployKeys, k.ID)
+
+	switch {
+	case len(segs) == 2 && segs[1] == "enable" && r.Method == http.MethodPost:
+		if i < 0 {
+			p.deployKeys = append(p.deployKeys, k.ID)
+		}
+		writeJSON(w, http.StatusCreated, k)
+	case i < 0:
+		writeError(w, http.StatusNotFound, "404 Deploy Key Not Found")
+	case len(segs) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, k)
+	case (len(segs) == 1 || len(segs) == 2 && segs[1] == "disable") && r.Method == http.MethodDelete:
+		p.deployKeys = slices.Delete(p.deployKeys, i, i+1)
+		writeJSON(w, http.StatusOK, k)
+	default:
+		writeError(w, http.StatusNotFound, "404 Not Found")
+	}
+}
+
+func (s *Server) addDeployKey(w http.ResponseWriter, r *http.Request, p *project) {
+	var opts tgit.AddDeployKeyOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil || opts.Key == nil || opts.Title == nil {
+		writeError(w, http.StatusBadRequest, "400 (Bad request) \"title\" and \"key\" not given")
+		return
+	}
+
+	k := &tgit.DeployKey{ID: 1, Title: *opts.Title, Key: *opts.Key, CreatedAt: &tgit.Time{Time: time.Now()}}
+	if opts.CanPush != nil {
+		k.CanPush = *opts.CanPush
+	}
+	for _, v := range s.deployKeys {
+		k.ID = max(k.ID, v.ID+1)
+	}
+	s.deployKeys = append(s.deployKeys, k)
+	p.deployKeys = append(p.deployKeys, k.ID)
+	writeJSON(w, http.StatusCreated, k)
+}
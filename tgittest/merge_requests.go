@@ -0,0 +1,158 @@
+package tgittest
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	tgit "github.com/liwenqiu/go-tgit"
+)
+
+// AddMergeRequestDiffVersion seeds a diff version of the merge request with
+// the ID v.MergeRequestID. Versions are listed in the order they were added,
+// so add the newest first like the real service returns them.
+func (s *Server) AddMergeRequestDiffVersion(pid int64, v *tgit.MergeRequestDiffVersion) {
+	s.withProject(pid, func(p *project) { p.versions = append(p.versions, v) })
+}
+
+func (s *Server) serveMergeRequestVersions(w http.ResponseWriter, r *http.Request, p *project, id int64, segs []string) {
+	var versions []*tgit.MergeRequestDiffVersion
+	for _, v := range p.versions {
+		if v.MergeRequestID == id {
+			versions = append(versions, v)
+		}
+	}
+	if len(segs) == 0 {
+		writePage(w, r, versions)
+		return
+	}
+	for _, v := range versions {
+		if strconv.FormatInt(v.ID, 10) == segs[0] {
+			writeJSON(w, http.StatusOK, v)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "404 Version Not Found")
+}
+
+// serveAllMergeRequests lists the merge requests of every project, narrowed
+// by the scope relative to the current user and the usual filters.
+func (s *Server) serveAllMergeRequests(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+		return
+	}
+	q := r.URL.Query()
+	scope := q.Get("scope")
+	if scope != "" && scope != "all" && s.currentUser == nil {
+		writeError(w, http.StatusUnauthorized, "401 Unauthorized")
+		return
+	}
+
+	var mrs []*tgit.MergeRequest
+	for _, p := range s.projects {
+		for _, mr := range p.mergeRequests {
+			if matchesScope(mr, scope, s.currentUser) && matchesMergeRequest(mr, q) {
+				mrs = append(mrs, mr)
+			}
+		}
+	}
+	writePage(w, r, mrs)
+}
+
+func matchesScope(mr *tgit.MergeRequest, scope string, me *tgit.User) bool {
+	switch scope {
+	case "", "all":
+		return true
+	case "created_by_me":
+		return mr.Author != nil && mr.Author.ID == me.ID
+	case "assigned_to_me":
+		return mr.Assignee != nil && mr.Assignee.ID == me.ID
+	case "reviewed_by_me":
+		return hasReviewer(mr, me.ID)
+	case "involving_me":
+		return (mr.Author != nil && mr.Author.ID == me.ID) || (mr.Assignee != nil && mr.Assignee.ID == me.ID) || hasReviewer(mr, me.ID)
+	}
+	return false
+}
+
+func hasReviewer(mr *tgit.MergeRequest, id int64) bool {
+	for _, v := range mr.NecessaryReviewers {
+		if v.ID == id {
+			return true
+		}
+	}
+	for _, v := range mr.SuggestionReviewers {
+		if v.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMergeRequest applies the filters of ListMergeRequestsOptions.
+func matchesMergeRequest(mr *tgit.MergeRequest, q url.Values) bool {
+	if v := q.Get("state"); v != "" && v != "all" && mr.State != v {
+		return false
+	}
+	if v := q.Get("iid"); v != "" && strconv.FormatInt(mr.Iid, 10) != v {
+		return false
+	}
+	if v := q.Get("author_id"); v != "" && (mr.Author == nil || strconv.FormatInt(mr.Author.ID, 10) != v) {
+		return false
+	}
+	if v := q.Get("assignee_id"); v != "" && (mr.Assignee == nil || strconv.FormatInt(mr.Assignee.ID, 10) != v) {
+		return false
+	}
+	if v := q.Get("reviewer_id"); v != "" {
+		id, _ := strconv.ParseInt(v, 10, 64)
+		if !hasReviewer(mr, id) {
+			return false
+		}
+	}
+	if v := q.Get("labels"); v != "" {
+		for _, l := range strings.Split(v, ",") {
+			if !containsString(mr.Labels, l) {
+				return false
+			}
+		}
+	}
+	if v := q.Get("milestone"); v != "" && (mr.Milestone == nil || mr.Milestone.Title != v) {
+		return false
+	}
+	if v := q.Get("source_branch"); v != "" && mr.SourceBranch != v {
+		return false
+	}
+	if v := q.Get("target_branch"); v != "" && mr.TargetBranch != v {
+		return false
+	}
+	return inRange(mr.CreatedAt, q.Get("created_after"), q.Get("created_before")) &&
+		inRange(mr.UpdatedAt, q.Get("updated_after"), q.Get("updated_before"))
+}
+
+func inRange(t *tgit.Time, after, before string) bool {
+	if after == "" && before == "" {
+		return true
+	}
+	if t == nil {
+		return false
+	}
+	if a, err := time.Parse(time.RFC3339, after); err == nil && t.Before(a) {
+		return false
+	}
+	if b, err := time.Parse(time.RFC3339, before); err == nil && t.After(b) {
+		return false
+	}
+	return true
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package tgittest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	tgit "github.com/liwenqiu/go-tgit"
+)
+
+// AddProjectHook seeds a webhook of the project.
+func (s *Server) AddProjectHook(pid int64, h *tgit.ProjectHook) {
+	s.withProject(pid, func(p *project) {
+		h.ProjectID = p.item.ID
+		p.hooks = append(p.hooks, h)
+	})
+}
+
+// serveProjectHooks answers the routes below projects/:id/hooks.
+func (s *Server) serveProjectHooks(w http.ResponseWriter, r *http.Request, p *project, segs []string) {
+	if len(segs) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			writePage(w, r, p.hooks)
+		case http.MethodPost:
+			s.addProjectHook(w, r, p)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+		}
+		return
+	}
+
+	for i, h := range p.hooks {
+		if strconv.FormatInt(h.ID, 10) != segs[0] {
+			continue
+		}
+		switch {
+		case len(segs) == 1 && r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, h)
+		case len(segs) == 1 && r.Method == http.MethodPut:
+			var opts tgit.EditProjectHookOptions
+			if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+				writeError(w, http.StatusBadRequest, "400 Bad Request")
+				return
+			}
+			applyProjectHook(h, tgit.AddProjectHookOptions(opts))
+			writeJSON(w, http.StatusOK, h)
+		case len(segs) == 1 && r.Method == http.MethodDelete:
+			p.hooks = append(p.hooks[:i], p.hooks[i+1:]...)
+			writeJSON(w, http.StatusOK, h)
+		default:
+			writeError(w, http.StatusNotFound, "404 Not Found")
+		}
+		return
+	}
+	writeError(w, http.StatusNotFound, "404 Hook Not Found")
+}
+
+func (s *Server) addProjectHook(w http.ResponseWriter, r *http.Request, p *project) {
+	var opts tgit.AddProjectHookOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil || opts.URL == nil {
+		writeError(w, http.StatusBadRequest, "400 (Bad request) \"url\" not given")
+		return
+	}
+
+	h := &tgit.ProjectHook{ID: 1, ProjectID: p.item.ID, CreatedAt: &tgit.Time{Time: time.Now()}}
+	for _, v := range p.hooks {
+		h.ID = max(h.ID, v.ID+1)
+	}
+	applyProjectHook(h, opts)
+	p.hooks = append(p.hooks, h)
+	writeJSON(w, http.StatusCreated, h)
+}
+
+// applyProjectHook copies the sent settings onto h, the token is write-only
+// and not kept.
+func applyProjectHook(h *tgit.ProjectHook, opts tgit.AddProjectHookOptions) {
+	if opts.URL != nil {
+		h.URL = *opts.URL
+	}
+	for _, f := range []struct {
+		dst *bool
+		src *bool
+	}{
+		{&h.PushEvents, opts.PushEvents},
+		{&h.TagPushEvents, opts.TagPushEvents},
+		{&h.MergeRequestsEvents, opts.MergeRequestsEvents},
+		{&h.IssuesEvents, opts.IssuesEvents},
+		{&h.NoteEvents, opts.NoteEvents},
+		{&h.ReviewEvents, opts.ReviewEvents},
+	} {
+		if f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package tgittest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	tgit "github.com/liwenqiu/go-tgit"
+)
+
+// AddNamespace seeds a namespace projects can be created in, forked into or
+// transferred to.
+func (s *Server) AddNamespace(ns *tgit.ProjectNamespace) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.namespaces = append(s.namespaces, ns)
+}
+
+// namespace returns the namespace with the given ID, or the one of the
+// current user when id is nil.
+func (s *Server) namespace(id *int64) *tgit.ProjectNamespace {
+	if id == nil {
+		if s.currentUser == nil {
+			return nil
+		}
+		return &tgit.ProjectNamespace{ID: s.currentUser.ID, Name: s.currentUser.Username, Path: s.currentUser.Username, OwnerID: s.currentUser.ID}
+	}
+	for _, ns := range s.namespaces {
+		if ns.ID == *id {
+			return ns
+		}
+	}
+	return nil
+}
+
+func (s *Server) nextProjectID() int64 {
+	var id int64
+	for _, p := range s.projects {
+		if p.item.ID > id {
+			id = p.item.ID
+		}
+	}
+	return id + 1
+}
+
+func (s *Server) addProjectLocked(item *tgit.ProjectItem, ns *tgit.ProjectNamespace) bool {
+	item.Namespace = ns
+	item.PathWithNamespace = ns.Path + "/" + item.Path
+	item.NameWithNamespace = ns.Name + " / " + item.Name
+	if s.findProject(item.PathWithNamespace) != nil {
+		return false
+	}
+	if item.DefaultBranch == "" {
+		item.DefaultBranch = defaultBranch
+	}
+	s.projects = append(s.projects, newProject(item))
+	return true
+}
+
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request) {
+	var opts tgit.CreateProjectOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil || opts.Name == nil {
+		writeError(w, http.StatusBadRequest, "400 (Bad request) \"name\" not given")
+		return
+	}
+	ns := s.namespace(opts.NamespaceID)
+	if ns == nil {
+		writeError(w, http.StatusNotFound, "404 Namespace Not Found")
+		return
+	}
+
+	item := &tgit.ProjectItem{ID: s.nextProjectID(), Name: *opts.Name, Path: *opts.Name}
+	if opts.Path != nil {
+		item.Path = *opts.Path
+	}
+	if opts.Description != nil {
+		item.Description = *opts.Description
+	}
+	if opts.VisibilityLevel != nil {
+		item.VisibilityLevel = *opts.VisibilityLevel
+	}
+	if !s.addProjectLocked(item, ns) {
+		writeError(w, http.StatusBadRequest, "400 Path has already been taken")
+		return
+	}
+	writeJSON(w, http.StatusCreated, item)
+}
+
+// editProject applies the sent settings onto the project. Settings that are
+// sent in another shape than they are returned, like the reviewer lists, are
+// ignored.
+func (s *Server) editProject(w http.ResponseWriter, r *http.Request, p *project) {
+	var typeErr *json.UnmarshalTypeError
+	if err := json.NewDecoder(r.Body).Decode(p.item); err != nil && !errors.As(err, &typeErr) {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	if p.item.Namespace != nil {
+		p.item.PathWithNamespace = p.item.Namespace.Path + "/" + p.item.Path
+	}
+	writeJSON(w, http.StatusOK, p.item)
+}
+
+func (s *Server) forkProject(w http.ResponseWriter, r *http.Request, id string) {
+	source := s.findProject(id)
+	if source == nil {
+		writeError(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+	var opts tgit.ForkProjectOptions
+	json.NewDecoder(r.Body).Decode(&opts)
+	ns := s.namespace(opts.NamespaceID)
+	if ns == nil {
+		writeError(w, http.StatusNotFound, "404 Namespace Not Found")
+		return
+	}
+
+	fork := *source.item
+	fork.ID = s.nextProjectID()
+	fork.ForksCount = 0
+	fork.ForkedFromProject = map[string]interface{}{
+		"id":                  source.item.ID,
+		"path_with_namespace": source.item.PathWithNamespace,
+	}
+	if !s.addProjectLocked(&fork, ns) {
+		writeError(w, http.StatusConflict, "409 Project already forked")
+		return
+	}
+	source.item.ForksCount++
+	writeJSON(w, http.StatusCreated, &fork)
+}
+
+func (s *Server) deleteProject(w http.ResponseWriter, p *project) {
+	for i, q := range s.projects {
+		if q == p {
+			s.projects = append(s.projects[:i], s.projects[i+1:]...)
+			break
+		}
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"message": "202 Accepted"})
+}
+
+func (s *Server) transferProject(w http.ResponseWriter, r *http.Request, p *project) {
+	var opts tgit.TransferProjectOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil || opts.NamespaceID == nil {
+		writeError(w, http.StatusBadRequest, "400 (Bad request) \"namespace_id\" not given")
+		return
+	}
+	ns := s.namespace(opts.NamespaceID)
+	if ns == nil {
+		writeError(w, http.StatusNotFound, "404 Namespace Not Found")
+		return
+	}
+	if s.findProject(ns.Path+"/"+p.item.Path) != nil {
+		writeError(w, http.StatusBadRequest, "400 Path has already been taken")
+		return
+	}
+	p.item.Namespace = ns
+	p.item.PathWithNamespace = ns.Path + "/" + p.item.Path
+	p.item.NameWithNamespace = ns.Name + " / " + p.item.Name
+	writeJSON(w, http.StatusOK, p.item)
+}
@@ -0,0 +1,299 @@
+package tgittest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type Mode int
+
+const (
+	// ModeRecord forwards requests to the real transport and records them.
+	ModeRecord Mode = iota
+
+	// ModeReplay answers requests from a golden file without network access.
+	ModeReplay
+)
+
+const redacted = "REDACTED"
+
+// redactedHeaders never end up in a golden file.
+var redactedHeaders = []string{"PRIVATE-TOKEN", "OAUTH-TOKEN", "Authorization", "Cookie", "Set-Cookie"}
+
+// redactedFields are the query parameters, form fields and JSON object keys
+// whose values never end up in a golden file: session passwords, OAuth codes
+// and tokens, access token values and webhook secrets.
+var redactedFields = map[string]bool{
+	"access_token":  true,
+	"client_secret": true,
+	"code":          true,
+	"password":      true,
+	"private_token": true,
+	"refresh_token": true,
+	"token":         true,
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+type Interaction struct {
+	Request  *RecordedRequest  `json:"request"`
+	Response *RecordedResponse `json:"response"`
+}
+
+// Recorder is an http.RoundTripper that records interactions to a golden file
+// or replays them from it. Token headers and secrets in query parameters and
+// JSON or form bodies, like passwords, OAuth codes and access tokens, are
+// redacted before recording. Set it as the Transport of the HTTP client passed
+// to tgit.NewClient:
+//
+//	rec, _ := tgittest.NewRecorder("testdata/projects.json", tgittest.ModeReplay, nil)
+//	hc := retryablehttp.NewClient()
+//	hc.HTTPClient.Transport = rec
+//	c, _ := tgit.NewClient(hc, os.Getenv("TGIT_TOKEN"))
+type Recorder struct {
+	mode      Mode
+	path      string
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+// NewRecorder returns a Recorder backed by the golden file at path. In record
+// mode requests are sent through transport, http.DefaultTransport when nil.
+// In replay mode the golden file is loaded immediately.
+func NewRecorder(path string, mode Mode, transport http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{mode: mode, path: path, transport: transport}
+	if r.transport == nil {
+		r.transport = http.DefaultTransport
+	}
+
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &r.interactions); err != nil {
+			return nil, fmt.Errorf("tgittest: invalid golden file %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.interactions))
+	}
+
+	return r, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req)
+	}
+	return r.record(req, body)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.interactions = append(r.interactions, &Interaction{
+		Request: &RecordedRequest{
+			Method: req.Method,
+			Path:   req.URL.EscapedPath(),
+			Query:  normalizeQuery(redactValues(req.URL.Query())),
+			Header: redact(req.Header),
+			Body:   redactBody(req.Header, body),
+		},
+		Response: &RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     redact(resp.Header),
+			Body:       redactBody(resp.Header, respBody),
+		},
+	})
+
+	return resp, nil
+}
+
+// replay answers with the first unused interaction matching the method, path
+// and normalized, redacted query of req, falling back to the last used match so
+// repeated calls keep working.
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path, query := req.URL.EscapedPath(), normalizeQuery(redactValues(req.URL.Query()))
+
+	match := -1
+	for i, in := range r.interactions {
+		if in.Request.Method != req.Method || in.Request.Path != path || in.Request.Query != query {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("tgittest: no recorded interaction for %s %s?%s", req.Method, path, query)
+	}
+	r.used[match] = true
+
+	rec := r.interactions[match].Response
+	header := rec.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+		StatusCode:    rec.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(rec.Body))),
+		ContentLength: int64(len(rec.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Save writes the recorded interactions to the golden file, it is a no-op in
+// replay mode.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+// normalizeQuery encodes q with sorted keys and values so that parameter
+// order does not affect matching.
+func normalizeQuery(q url.Values) string {
+	for _, v := range q {
+		sort.Strings(v)
+	}
+	return q.Encode()
+}
+
+func redact(h http.Header) http.Header {
+	h = h.Clone()
+	for _, k := range redactedHeaders {
+		if h.Get(k) != "" {
+			h.Set(k, redacted)
+		}
+	}
+	return h
+}
+
+func redactValues(v url.Values) url.Values {
+	for k := range v {
+		if redactedFields[k] {
+			v[k] = []string{redacted}
+		}
+	}
+	return v
+}
+
+// redactBody redacts the secret fields of a JSON or form encoded body, other
+// bodies are kept as they are.
+func redactBody(h http.Header, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if strings.HasPrefix(h.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if v, err := url.ParseQuery(string(body)); err == nil {
+			return redactValues(v).Encode()
+		}
+		return string(body)
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+	if !redactJSON(v) {
+		return string(body)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return string(body)
+	}
+	return string(data)
+}
+
+// redactJSON replaces the values of secret keys in v and reports whether any
+// was found.
+func redactJSON(v interface{}) bool {
+	found := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if redactedFields[k] {
+				if s, ok := e.(string); ok && s != "" {
+					v[k] = redacted
+					found = true
+				}
+				continue
+			}
+			found = redactJSON(e) || found
+		}
+	case []interface{}:
+		for _, e := range v {
+			found = redactJSON(e) || found
+		}
+	}
+	return found
+}
@@ -0,0 +1,269 @@
+package tgittest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	tgit "github.com/liwenqiu/go-tgit"
+)
+
+// reviewStates maps the reviewer events onto the review state they leave
+// the reviewer in, a comment leaves it unchanged.
+var reviewStates = map[tgit.ReviewEventValue]string{
+	tgit.ReviewApprove:       "approved",
+	tgit.ReviewRequireChange: "change_required",
+	tgit.ReviewDeny:          "change_denied",
+	tgit.ReviewReset:         "reviewing",
+}
+
+// AddReview seeds a code review, an empty State stands for "opened".
+func (s *Server) AddReview(pid int64, rv *tgit.Review) {
+	s.withProject(pid, func(p *project) {
+		if rv.State == "" {
+			rv.State = "opened"
+		}
+		rv.ProjectID = p.item.ID
+		p.reviews = append(p.reviews, rv)
+	})
+}
+
+func (s *Server) serveReviews(w http.ResponseWriter, r *http.Request, p *project) {
+	q := r.URL.Query()
+	var reviews []*tgit.Review
+	for _, rv := range p.reviews {
+		if v := q.Get("state"); v != "" && v != "all" && rv.State != v {
+			continue
+		}
+		if v := q.Get("author_id"); v != "" && (rv.Author == nil || strconv.FormatInt(rv.Author.ID, 10) != v) {
+			continue
+		}
+		reviews = append(reviews, rv)
+	}
+	writePage(w, r, reviews)
+}
+
+// serveReview answers the routes below projects/:id/review.
+func (s *Server) serveReview(w http.ResponseWriter, r *http.Request, p *project, segs []string) {
+	if len(segs) == 0 {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+			return
+		}
+		s.createReview(w, r, p)
+		return
+	}
+
+	var rv *tgit.Review
+	for _, v := range p.reviews {
+		if strconv.FormatInt(v.ID, 10) == segs[0] {
+			rv = v
+		}
+	}
+	if rv == nil {
+		writeError(w, http.StatusNotFound, "404 Review Not Found")
+		return
+	}
+
+	switch {
+	case len(segs) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, rv)
+	case len(segs) == 1 && r.Method == http.MethodPut:
+		s.updateReview(w, r, rv)
+	case len(segs) == 2 && segs[1] == "reviewers" && r.Method == http.MethodPost:
+		s.addReviewers(w, r, rv)
+	case len(segs) == 3 && segs[1] == "reviewers" && r.Method == http.MethodDelete:
+		for i, v := range rv.Reviewers {
+			if strconv.FormatInt(v.ID, 10) == segs[2] {
+				rv.Reviewers = append(rv.Reviewers[:i], rv.Reviewers[i+1:]...)
+				writeJSON(w, http.StatusOK, v)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "404 Reviewer Not Found")
+	case len(segs) == 3 && segs[1] == "reviewer" && segs[2] == "summary" && r.Method == http.MethodPut:
+		s.setReviewState(w, r, rv)
+	case len(segs) == 2 && segs[1] == "notes" && r.Method == http.MethodGet:
+		writePage(w, r, p.reviewNotes[rv.ID])
+	case len(segs) == 2 && segs[1] == "notes" && r.Method == http.MethodPost:
+		s.createReviewNote(w, r, p, rv)
+	default:
+		writeError(w, http.StatusNotFound, "404 Not Found")
+	}
+}
+
+func (s *Server) createReview(w http.ResponseWriter, r *http.Request, p *project) {
+	var opts tgit.CreateReviewOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil || opts.Title == nil {
+		writeError(w, http.StatusBadRequest, "400 (Bad request) \"title\" not given")
+		return
+	}
+
+	rv := &tgit.Review{
+		ID:             1,
+		Iid:            1,
+		ProjectID:      p.item.ID,
+		Title:          *opts.Title,
+		State:          "opened",
+		ReviewableType: "review",
+		Labels:         opts.Labels,
+	}
+	for _, v := range p.reviews {
+		rv.ID = max(rv.ID, v.ID+1)
+		rv.Iid = max(rv.Iid, v.Iid+1)
+	}
+	for _, f := range []struct {
+		dst *string
+		src *string
+	}{
+		{&rv.Description, opts.Description},
+		{&rv.SourceBranch, opts.SourceBranch},
+		{&rv.SourceCommit, opts.SourceCommit},
+		{&rv.TargetBranch, opts.TargetBranch},
+		{&rv.TargetCommit, opts.TargetCommit},
+	} {
+		if f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+	if u := s.currentUser; u != nil {
+		rv.Author = &tgit.MergeRequestUser{ID: u.ID, Username: u.Username, Name: u.Name}
+	}
+	if !s.appendReviewers(rv, opts.ReviewerIDs, "suggestion") {
+		writeError(w, http.StatusNotFound, "404 User Not Found")
+		return
+	}
+
+	p.reviews = append(p.reviews, rv)
+	writeJSON(w, http.StatusCreated, rv)
+}
+
+func (s *Server) updateReview(w http.ResponseWriter, r *http.Request, rv *tgit.Review) {
+	var opts tgit.UpdateReviewOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+
+	if opts.StateEvent != nil {
+		switch {
+		case *opts.StateEvent == "close" && rv.State != "closed":
+			rv.State = "closed"
+		case *opts.StateEvent == "reopen" && rv.State == "closed":
+			rv.State = "reopened"
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+			return
+		}
+	}
+	if opts.Title != nil {
+		rv.Title = *opts.Title
+	}
+	if opts.Description != nil {
+		rv.Description = *opts.Description
+	}
+	if opts.Labels != nil {
+		rv.Labels = opts.Labels
+	}
+	writeJSON(w, http.StatusOK, rv)
+}
+
+func (s *Server) addReviewers(w http.ResponseWriter, r *http.Request, rv *tgit.Review) {
+	var opts tgit.AddReviewersOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil || len(opts.ReviewerIDs) == 0 {
+		writeError(w, http.StatusBadRequest, "400 (Bad request) \"reviewer_ids\" not given")
+		return
+	}
+
+	typ := "suggestion"
+	if opts.Necessary != nil && *opts.Necessary {
+		typ = "necessary"
+	}
+	if !s.appendReviewers(rv, opts.ReviewerIDs, typ) {
+		writeError(w, http.StatusNotFound, "404 User Not Found")
+		return
+	}
+	writeJSON(w, http.StatusCreated, rv.Reviewers)
+}
+
+// appendReviewers adds the seeded users with the given IDs that are not
+// reviewers yet, it reports false when one of them does not exist.
+func (s *Server) appendReviewers(rv *tgit.Review, ids []int64, typ string) bool {
+	var added []*tgit.MergeRequestViewer
+	for _, id := range ids {
+		u := s.findUser(strconv.FormatInt(id, 10))
+		if u == nil {
+			return false
+		}
+		if reviewer(rv, id) != nil {
+			continue
+		}
+		added = append(added, &tgit.MergeRequestViewer{Type: typ, ReviewState: "reviewing", ID: u.ID, Username: u.Username, Name: u.Name})
+	}
+	rv.Reviewers = append(rv.Reviewers, added...)
+	return true
+}
+
+func reviewer(rv *tgit.Review, id int64) *tgit.MergeRequestViewer {
+	for _, v := range rv.Reviewers {
+		if v.ID == id {
+			return v
+		}
+	}
+	return nil
+}
+
+// setReviewState applies the event of the current user, who must be one of
+// the reviewers of an open review.
+func (s *Server) setReviewState(w http.ResponseWriter, r *http.Request, rv *tgit.Review) {
+	if s.currentUser == nil {
+		writeError(w, http.StatusUnauthorized, "401 Unauthorized")
+		return
+	}
+	v := reviewer(rv, s.currentUser.ID)
+	if v == nil {
+		writeError(w, http.StatusForbidden, "403 Forbidden")
+		return
+	}
+	if rv.State == "closed" {
+		writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+		return
+	}
+
+	var opts tgit.SetReviewStateOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil || opts.ReviewerEvent == nil {
+		writeError(w, http.StatusBadRequest, "400 (Bad request) \"reviewer_event\" not given")
+		return
+	}
+	if state, ok := reviewStates[*opts.ReviewerEvent]; ok {
+		v.ReviewState = state
+	} else if *opts.ReviewerEvent != tgit.ReviewComment {
+		writeError(w, http.StatusBadRequest, "400 (Bad request) \"reviewer_event\" is invalid")
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+func (s *Server) createReviewNote(w http.ResponseWriter, r *http.Request, p *project, rv *tgit.Review) {
+	var opts tgit.CreateReviewNoteOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil || opts.Body == nil {
+		writeError(w, http.StatusBadRequest, "400 (Bad request) \"body\" not given")
+		return
+	}
+
+	n := &tgit.ReviewNote{ID: int64(len(p.reviewNotes[rv.ID]) + 1), Body: *opts.Body, CommitID: rv.SourceCommit}
+	if opts.Path != nil {
+		n.Path = *opts.Path
+	}
+	if opts.Line != nil {
+		n.Line = *opts.Line
+	}
+	if opts.LineType != nil {
+		n.LineType = *opts.LineType
+	}
+	if u := s.currentUser; u != nil {
+		n.Author = &tgit.MergeRequestUser{ID: u.ID, Username: u.Username, Name: u.Name}
+	}
+	p.reviewNotes[rv.ID] = append(p.reviewNotes[rv.ID], n)
+	writeJSON(w, http.StatusCreated, n)
+}
@@ -0,0 +1,754 @@
+// Package tgittest provides an in-memory fake TGit server for tests.
+//
+// The server implements the subset of the API covered by the tgit package:
+// projects, project hooks, deploy keys, namespaces, branches, tags, commits,
+// compares, contributors, repository files and blames, merge requests with
+// their diff versions, code reviews and users. It is seeded from Go structs
+// and answers with the pagination headers and error bodies of the real
+// service.
+package tgittest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	tgit "github.com/liwenqiu/go-tgit"
+)
+
+const (
+	apiPrefix      = "/api/v3/"
+	defaultPerPage = 20
+	maxPerPage     = 100
+	defaultBranch  = "master"
+)
+
+type project struct {
+	item          *tgit.ProjectItem
+	branches      []*tgit.Branch
+	tags          []*tgit.Tag
+	commits       []*tgit.Commit
+	statuses      []*tgit.CommitStatus
+	files         map[string]*tgit.File
+	mergeRequests []*tgit.MergeRequest
+	changes       map[int64]*tgit.MergeRequestChange
+	versions      []*tgit.MergeRequestDiffVersion
+	diffs         map[string][]*tgit.Diff
+	reviews       []*tgit.Review
+	reviewNotes   map[int64][]*tgit.ReviewNote
+	blames        map[string][]*tgit.BlameRange
+	hooks         []*tgit.ProjectHook
+	deployKeys    []int64
+}
+
+// Server is a fake TGit server. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// Token, when set, must be sent as PRIVATE-TOKEN or OAUTH-TOKEN.
+	Token string
+
+	mu          sync.Mutex
+	projects    []*project
+	namespaces  []*tgit.ProjectNamespace
+	users       []*tgit.User
+	currentUser *tgit.User
+	emails      []*tgit.Email
+	deployKeys  []*tgit.DeployKey
+	failures    int
+	failureCode int
+}
+
+// NewServer starts a fake server, callers should Close it when done.
+func NewServer() *Server {
+	s := new(Server)
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewClient returns a client talking to s, retries are disabled so errors
+// surface immediately.
+func (s *Server) NewClient() (*tgit.Client, error) {
+	hc := retryablehttp.NewClient()
+	hc.Logger = nil
+	hc.RetryMax = 0
+
+	c, err := tgit.NewClient(hc, s.Token)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.SetBaseURL(s.URL); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *Server) AddProject(p *tgit.ProjectItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p.DefaultBranch == "" {
+		p.DefaultBranch = defaultBranch
+	}
+	s.projects = append(s.projects, newProject(p))
+}
+
+func newProject(item *tgit.ProjectItem) *project {
+	return &project{
+		item:        item,
+		files:       make(map[string]*tgit.File),
+		changes:     make(map[int64]*tgit.MergeRequestChange),
+		diffs:       make(map[string][]*tgit.Diff),
+		reviewNotes: make(map[int64][]*tgit.ReviewNote),
+		blames:      make(map[string][]*tgit.BlameRange),
+	}
+}
+
+func (s *Server) AddBranch(pid int64, b *tgit.Branch) {
+	s.withProject(pid, func(p *project) { p.branches = append(p.branches, b) })
+}
+
+func (s *Server) AddTag(pid int64, t *tgit.Tag) {
+	s.withProject(pid, func(p *project) { p.tags = append(p.tags, t) })
+}
+
+func (s *Server) AddCommit(pid int64, c *tgit.Commit) {
+	s.withProject(pid, func(p *project) { p.commits = append(p.commits, c) })
+}
+
+// AddCommitDiff seeds the diff of the commit sha. Commits listed with a path
+// filter are matched against the paths of their diff.
+func (s *Server) AddCommitDiff(pid int64, sha string, diffs []*tgit.Diff) {
+	s.withProject(pid, func(p *project) { p.diffs[sha] = diffs })
+}
+
+func (s *Server) AddCommitStatus(pid int64, st *tgit.CommitStatus) {
+	s.withProject(pid, func(p *project) { p.statuses = append(p.statuses, st) })
+}
+
+// AddFile seeds a file, an empty Ref stands for the default branch.
+func (s *Server) AddFile(pid int64, f *tgit.File) {
+	s.withProject(pid, func(p *project) {
+		if f.Ref == "" {
+			f.Ref = p.item.DefaultBranch
+		}
+		p.files[fileKey(f.Ref, f.FilePath)] = f
+	})
+}
+
+// AddBlame seeds the blame of filePath at ref, an empty ref stands for the
+// default branch.
+func (s *Server) AddBlame(pid int64, ref, filePath string, ranges []*tgit.BlameRange) {
+	s.withProject(pid, func(p *project) {
+		if ref == "" {
+			ref = p.item.DefaultBranch
+		}
+		p.blames[fileKey(ref, filePath)] = ranges
+	})
+}
+
+func (s *Server) AddMergeRequest(pid int64, mr *tgit.MergeRequest) {
+	s.withProject(pid, func(p *project) { p.mergeRequests = append(p.mergeRequests, mr) })
+}
+
+// AddMergeRequestChange seeds the changes returned for the merge request with
+// the ID of c.
+func (s *Server) AddMergeRequestChange(pid int64, c *tgit.MergeRequestChange) {
+	s.withProject(pid, func(p *project) { p.changes[c.ID] = c })
+}
+
+func (s *Server) AddUser(u *tgit.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users = append(s.users, u)
+}
+
+// SetCurrentUser sets the user returned for the authenticated user.
+func (s *Server) SetCurrentUser(u *tgit.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.currentUser = u
+}
+
+func (s *Server) withProject(pid int64, fn func(p *project)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.findProject(strconv.FormatInt(pid, 10))
+	if p == nil {
+		panic(fmt.Sprintf("tgittest: project %d not found, add it first", pid))
+	}
+	fn(p)
+}
+
+// findProject looks a project up by numeric ID or by namespace/path.
+func (s *Server) findProject(id string) *project {
+	for _, p := range s.projects {
+		if strconv.FormatInt(p.item.ID, 10) == id || p.item.PathWithNamespace == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func fileKey(ref, filePath string) string {
+	return ref + "\x00" + filePath
+}
+
+// FailNext makes the next n requests fail with the status code, to exercise
+// the handling of server errors and rate limiting.
+func (s *Server) FailNext(n, code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures, s.failureCode = n, code
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.Token != "" && r.Header.Get("PRIVATE-TOKEN") != s.Token && r.Header.Get("OAUTH-TOKEN") != s.Token {
+		writeError(w, http.StatusUnauthorized, "401 Unauthorized")
+		return
+	}
+
+	escaped := r.URL.EscapedPath()
+	if !strings.HasPrefix(escaped, apiPrefix) {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+
+	// Split on the escaped path so "namespace%2Fproject" stays one segment.
+	var segs []string
+	for _, seg := range strings.Split(strings.TrimPrefix(escaped, apiPrefix), "/") {
+		unescaped, err := url.PathUnescape(seg)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "400 Bad Request")
+			return
+		}
+		segs = append(segs, unescaped)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		writeError(w, s.failureCode, fmt.Sprintf("%d %s", s.failureCode, http.StatusText(s.failureCode)))
+		return
+	}
+
+	switch segs[0] {
+	case "projects":
+		s.serveProjects(w, r, segs[1:])
+	case "merge_requests":
+		s.serveAllMergeRequests(w, r)
+	case "user":
+		s.serveCurrentUser(w, r, segs[1:])
+	case "users":
+		s.serveUsers(w, r, segs[1:])
+	default:
+		writeError(w, http.StatusNotFound, "404 Not Found")
+	}
+}
+
+func (s *Server) serveProjects(w http.ResponseWriter, r *http.Request, segs []string) {
+	if len(segs) == 0 && r.Method == http.MethodPost {
+		s.createProject(w, r)
+		return
+	}
+	if len(segs) == 2 && segs[0] == "fork" && r.Method == http.MethodPost {
+		s.forkProject(w, r, segs[1])
+		return
+	}
+	if len(segs) == 0 {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+			return
+		}
+		search := r.URL.Query().Get("search")
+		var items []*tgit.ProjectItem
+		for _, p := range s.projects {
+			if search == "" || strings.Contains(p.item.Name, search) || strings.Contains(p.item.PathWithNamespace, search) {
+				items = append(items, p.item)
+			}
+		}
+		writePage(w, r, items)
+		return
+	}
+
+	p := s.findProject(segs[0])
+	if p == nil {
+		writeError(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+
+	switch {
+	case len(segs) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, p.item)
+	case len(segs) == 1 && r.Method == http.MethodPut:
+		s.editProject(w, r, p)
+	case len(segs) == 1 && r.Method == http.MethodDelete:
+		s.deleteProject(w, p)
+	case len(segs) == 2 && (segs[1] == "archive" || segs[1] == "unarchive") && r.Method == http.MethodPost:
+		p.item.Archived = segs[1] == "archive"
+		writeJSON(w, http.StatusCreated, p.item)
+	case len(segs) == 2 && segs[1] == "transfer" && r.Method == http.MethodPut:
+		s.transferProject(w, r, p)
+	case len(segs) >= 2 && segs[1] == "deploy_keys":
+		s.serveDeployKeys(w, r, p, segs[2:])
+	case len(segs) >= 2 && segs[1] == "hooks":
+		s.serveProjectHooks(w, r, p, segs[2:])
+	case len(segs) >= 3 && segs[1] == "repository" && segs[2] == "branches":
+		s.serveBranches(w, r, p, segs[3:])
+	case len(segs) >= 3 && segs[1] == "repository" && segs[2] == "tags":
+		s.serveTags(w, r, p, segs[3:])
+	case len(segs) >= 3 && segs[1] == "repository" && segs[2] == "commits":
+		s.serveCommits(w, r, p, segs[3:])
+	case len(segs) == 3 && segs[1] == "repository" && segs[2] == "contributors" && r.Method == http.MethodGet:
+		s.serveContributors(w, r, p)
+	case len(segs) == 3 && segs[1] == "repository" && segs[2] == "compare" && r.Method == http.MethodGet:
+		s.serveCompare(w, r, p)
+	case len(segs) >= 4 && segs[1] == "merge_request" && segs[3] == "versions" && r.Method == http.MethodGet:
+		id, _ := strconv.ParseInt(segs[2], 10, 64)
+		s.serveMergeRequestVersions(w, r, p, id, segs[4:])
+	case len(segs) == 2 && segs[1] == "reviews" && r.Method == http.MethodGet:
+		s.serveReviews(w, r, p)
+	case len(segs) >= 2 && segs[1] == "review":
+		s.serveReview(w, r, p, segs[2:])
+	case len(segs) == 3 && segs[1] == "repository" && segs[2] == "blame" && r.Method == http.MethodGet:
+		s.serveBlame(w, r, p)
+	case len(segs) == 3 && segs[1] == "repository" && segs[2] == "files":
+		s.serveFiles(w, r, p)
+	case len(segs) == 2 && segs[1] == "merge_requests" && r.Method == http.MethodGet:
+		s.serveMergeRequests(w, r, p)
+	case len(segs) == 3 && segs[1] == "merge_request" && r.Method == http.MethodGet:
+		id, _ := strconv.ParseInt(segs[2], 10, 64)
+		for _, mr := range p.mergeRequests {
+			if mr.ID == id {
+				writeJSON(w, http.StatusOK, mr)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "404 Merge Request Not Found")
+	case len(segs) == 4 && segs[1] == "merge_request" && segs[3] == "merge" && r.Method == http.MethodPut:
+		id, _ := strconv.ParseInt(segs[2], 10, 64)
+		s.acceptMergeRequest(w, r, p, id)
+	case len(segs) == 4 && segs[1] == "merge_request" && segs[3] == "changes" && r.Method == http.MethodGet:
+		id, _ := strconv.ParseInt(segs[2], 10, 64)
+		c, ok := p.changes[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, "404 Merge Request Not Found")
+			return
+		}
+		writeJSON(w, http.StatusOK, c)
+	default:
+		writeError(w, http.StatusNotFound, "404 Not Found")
+	}
+}
+
+func (s *Server) serveBranches(w http.ResponseWriter, r *http.Request, p *project, segs []string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+		return
+	}
+	if len(segs) == 0 {
+		writePage(w, r, p.branches)
+		return
+	}
+
+	name := strings.Join(segs, "/")
+	for _, b := range p.branches {
+		if b.Name == name {
+			writeJSON(w, http.StatusOK, b)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "404 Branch Not Found")
+}
+
+func (s *Server) serveTags(w http.ResponseWriter, r *http.Request, p *project, segs []string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+		return
+	}
+	if len(segs) == 0 {
+		writePage(w, r, p.tags)
+		return
+	}
+
+	name := strings.Join(segs, "/")
+	for _, t := range p.tags {
+		if t.Name == name {
+			writeJSON(w, http.StatusOK, t)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "404 Tag Not Found")
+}
+
+func (s *Server) serveCommits(w http.ResponseWriter, r *http.Request, p *project, segs []string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+		return
+	}
+	if len(segs) == 0 {
+		writePage(w, r, filterCommits(p, r.URL.Query()))
+		return
+	}
+
+	for _, c := range p.commits {
+		if c.ID == segs[0] || c.ShortID == segs[0] {
+			if len(segs) == 2 && segs[1] == "diff" {
+				writeJSON(w, http.StatusOK, p.diffs[c.ID])
+				return
+			}
+			if len(segs) == 2 && segs[1] == "refs" {
+				writePage(w, r, commitRefs(p, c.ID))
+				return
+			}
+			if len(segs) == 2 && segs[1] == "statuses" {
+				writePage(w, r, commitStatuses(p, c.ID))
+				return
+			}
+			writeJSON(w, http.StatusOK, c)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "404 Commit Not Found")
+}
+
+func filterCommits(p *project, q url.Values) []*tgit.Commit {
+	since, _ := time.Parse(time.RFC3339, q.Get("since"))
+	until, _ := time.Parse(time.RFC3339, q.Get("until"))
+	filePath := q.Get("path")
+
+	// Only the history of ref_name is listed, an unknown ref has none.
+	var reachable map[string]bool
+	if ref := q.Get("ref_name"); ref != "" {
+		reachable = ancestors(p, resolveRef(p, ref))
+	}
+
+	var commits []*tgit.Commit
+	for _, c := range p.commits {
+		if reachable != nil && !reachable[c.ID] {
+			continue
+		}
+		if c.CommittedDate != nil {
+			if !since.IsZero() && c.CommittedDate.Before(since) {
+				continue
+			}
+			if !until.IsZero() && c.CommittedDate.After(until) {
+				continue
+			}
+		}
+		if filePath != "" && !touchesPath(p.diffs[c.ID], filePath) {
+			continue
+		}
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+func touchesPath(diffs []*tgit.Diff, filePath string) bool {
+	for _, d := range diffs {
+		if d.NewPath == filePath || d.OldPath == filePath {
+			return true
+		}
+	}
+	return false
+}
+
+func commitRefs(p *project, sha string) []*tgit.CommitRef {
+	var refs []*tgit.CommitRef
+	for _, b := range p.branches {
+		if b.Commit != nil && b.Commit.ID == sha {
+			refs = append(refs, &tgit.CommitRef{Type: "branch", Name: b.Name})
+		}
+	}
+	for _, t := range p.tags {
+		if t.Commit != nil && t.Commit.ID == sha {
+			refs = append(refs, &tgit.CommitRef{Type: "tag", Name: t.Name})
+		}
+	}
+	return refs
+}
+
+func commitStatuses(p *project, sha string) []*tgit.CommitStatus {
+	var statuses []*tgit.CommitStatus
+	for _, st := range p.statuses {
+		if st.SHA == sha {
+			statuses = append(statuses, st)
+		}
+	}
+	return statuses
+}
+
+type fileRequest struct {
+	FilePath   string `json:"file_path"`
+	BranchName string `json:"branch_name"`
+	Encoding   string `json:"encoding"`
+	Content    string `json:"content"`
+}
+
+func (s *Server) serveBlame(w http.ResponseWriter, r *http.Request, p *project) {
+	q := r.URL.Query()
+	if q.Get("file_path") == "" {
+		writeError(w, http.StatusBadRequest, "400 (Bad request) \"file_path\" not given")
+		return
+	}
+	ref := q.Get("ref")
+	if ref == "" {
+		ref = p.item.DefaultBranch
+	}
+	ranges, ok := p.blames[fileKey(ref, q.Get("file_path"))]
+	if !ok {
+		writeError(w, http.StatusNotFound, "404 File Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, ranges)
+}
+
+func (s *Server) serveFiles(w http.ResponseWriter, r *http.Request, p *project) {
+	if r.Method == http.MethodGet {
+		ref := r.URL.Query().Get("ref")
+		if ref == "" {
+			ref = p.item.DefaultBranch
+		}
+		f, ok := p.files[fileKey(ref, r.URL.Query().Get("file_path"))]
+		if !ok {
+			writeError(w, http.StatusNotFound, "404 File Not Found")
+			return
+		}
+		writeJSON(w, http.StatusOK, f)
+		return
+	}
+
+	var req fileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.FilePath == "" || req.BranchName == "" {
+		writeError(w, http.StatusBadRequest, "400 (Bad request) \"file_path\" and \"branch_name\" not given")
+		return
+	}
+
+	key := fileKey(req.BranchName, req.FilePath)
+	_, exists := p.files[key]
+
+	switch r.Method {
+	case http.MethodPost:
+		if exists {
+			writeError(w, http.StatusBadRequest, "400 File already exists")
+			return
+		}
+	case http.MethodPut, http.MethodDelete:
+		if !exists {
+			writeError(w, http.StatusNotFound, "404 File Not Found")
+			return
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		delete(p.files, key)
+	} else {
+		content := req.Content
+		if req.Encoding != "base64" {
+			content = base64.StdEncoding.EncodeToString([]byte(content))
+		}
+		p.files[key] = &tgit.File{
+			FileName: path.Base(req.FilePath),
+			FilePath: req.FilePath,
+			Size:     base64.StdEncoding.DecodedLen(len(content)),
+			Encoding: "base64",
+			Content:  content,
+			Ref:      req.BranchName,
+		}
+	}
+
+	status := http.StatusOK
+	if r.Method == http.MethodPost {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, &tgit.FileInfo{
+		FilePath:   req.FilePath,
+		FileName:   path.Base(req.FilePath),
+		BranchName: req.BranchName,
+	})
+}
+
+func (s *Server) serveMergeRequests(w http.ResponseWriter, r *http.Request, p *project) {
+	q := r.URL.Query()
+	var mrs []*tgit.MergeRequest
+	for _, mr := range p.mergeRequests {
+		if matchesMergeRequest(mr, q) {
+			mrs = append(mrs, mr)
+		}
+	}
+	writePage(w, r, mrs)
+}
+
+// acceptMergeRequest merges an open merge request whose merge status allows
+// it, answering 405 otherwise and 409 when the given sha is not the source
+// commit.
+func (s *Server) acceptMergeRequest(w http.ResponseWriter, r *http.Request, p *project, id int64) {
+	var mr *tgit.MergeRequest
+	for _, m := range p.mergeRequests {
+		if m.ID == id {
+			mr = m
+		}
+	}
+	if mr == nil {
+		writeError(w, http.StatusNotFound, "404 Merge Request Not Found")
+		return
+	}
+
+	var req struct {
+		SHA string `json:"sha"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+
+	if (mr.State != "opened" && mr.State != "reopened") || (mr.MergeStatus != "" && mr.MergeStatus != "can_be_merged") {
+		writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+		return
+	}
+	if req.SHA != "" && req.SHA != mr.SourceCommit {
+		writeError(w, http.StatusConflict, "409 SHA does not match HEAD of source branch")
+		return
+	}
+
+	mr.State = "merged"
+	mr.MergeCommitSha = mr.SourceCommit
+	writeJSON(w, http.StatusOK, mr)
+}
+
+func (s *Server) serveCurrentUser(w http.ResponseWriter, r *http.Request, segs []string) {
+	if r.Method != http.MethodGet || len(segs) > 1 || (len(segs) == 1 && segs[0] != "emails") {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	if s.currentUser == nil {
+		writeError(w, http.StatusUnauthorized, "401 Unauthorized")
+		return
+	}
+	if len(segs) == 1 {
+		writePage(w, r, s.emails)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.currentUser)
+}
+
+func (s *Server) serveUsers(w http.ResponseWriter, r *http.Request, segs []string) {
+	if len(segs) == 0 && r.Method == http.MethodPost {
+		s.createUser(w, r)
+		return
+	}
+	if len(segs) == 0 && r.Method == http.MethodGet {
+		q := r.URL.Query()
+		var users []*tgit.User
+		for _, u := range s.users {
+			if v := q.Get("username"); v != "" && u.Username != v {
+				continue
+			}
+			if v := q.Get("email"); v != "" && u.Email != v {
+				continue
+			}
+			if v := q.Get("state"); v != "" && u.State != v {
+				continue
+			}
+			if v := q.Get("search"); v != "" && !strings.Contains(u.Username, v) && !strings.Contains(u.Name, v) && !strings.Contains(u.Email, v) {
+				continue
+			}
+			users = append(users, u)
+		}
+		writePage(w, r, users)
+		return
+	}
+
+	var u *tgit.User
+	if len(segs) > 0 {
+		u = s.findUser(segs[0])
+	}
+	if u == nil {
+		writeError(w, http.StatusNotFound, "404 User Not Found")
+		return
+	}
+
+	switch {
+	case len(segs) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, u)
+	case len(segs) == 1 && r.Method == http.MethodPut:
+		s.modifyUser(w, r, u)
+	case len(segs) == 2 && segs[1] == "block" && r.Method == http.MethodPut:
+		s.setUserState(w, u, "blocked")
+	case len(segs) == 2 && segs[1] == "unblock" && r.Method == http.MethodPut:
+		s.setUserState(w, u, "active")
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+	}
+}
+
+// writePage writes one page of items selected by the page and per_page query
+// parameters, together with the X-* pagination headers.
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+
+	total := len(items)
+	totalPages := (total + perPage - 1) / perPage
+
+	h := w.Header()
+	h.Set("X-Total", strconv.Itoa(total))
+	h.Set("X-Total-Pages", strconv.Itoa(totalPages))
+	h.Set("X-Per-Page", strconv.Itoa(perPage))
+	h.Set("X-Page", strconv.Itoa(page))
+	if page < totalPages {
+		h.Set("X-Next-Page", strconv.Itoa(page+1))
+	}
+	if page > 1 {
+		h.Set("X-Prev-Page", strconv.Itoa(page-1))
+	}
+
+	start := (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+
+	result := items[start:end]
+	if result == nil {
+		result = []T{}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
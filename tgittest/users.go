@@ -0,0 +1,101 @@
+package tgittest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	tgit "github.com/liwenqiu/go-tgit"
+)
+
+// AddEmail seeds an email of the current user.
+func (s *Server) AddEmail(e *tgit.Email) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.emails = append(s.emails, e)
+}
+
+func (s *Server) findUser(id string) *tgit.User {
+	for _, u := range s.users {
+		if u.Username == id || strconv.FormatInt(u.ID, 10) == id {
+			return u
+		}
+	}
+	return nil
+}
+
+// requireAdmin answers 403 unless the current user is an administrator.
+func (s *Server) requireAdmin(w http.ResponseWriter) bool {
+	if s.currentUser == nil || !s.currentUser.IsAdmin {
+		writeError(w, http.StatusForbidden, "403 Forbidden")
+		return false
+	}
+	return true
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w) {
+		return
+	}
+	var opts tgit.CreateUserOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil || opts.Email == nil || opts.Username == nil || opts.Name == nil {
+		writeError(w, http.StatusBadRequest, "400 (Bad request) \"email\", \"username\" and \"name\" are required")
+		return
+	}
+	if s.findUser(*opts.Username) != nil {
+		writeError(w, http.StatusConflict, "409 Username has already been taken")
+		return
+	}
+
+	var id int64
+	for _, u := range s.users {
+		if u.ID > id {
+			id = u.ID
+		}
+	}
+	u := &tgit.User{ID: id + 1, Email: *opts.Email, Username: *opts.Username, Name: *opts.Name, State: "active"}
+	if opts.Bio != nil {
+		u.Bio = *opts.Bio
+	}
+	if opts.Admin != nil {
+		u.IsAdmin = *opts.Admin
+	}
+	s.users = append(s.users, u)
+	writeJSON(w, http.StatusCreated, u)
+}
+
+func (s *Server) modifyUser(w http.ResponseWriter, r *http.Request, u *tgit.User) {
+	if !s.requireAdmin(w) {
+		return
+	}
+	var opts tgit.ModifyUserOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	if opts.Email != nil {
+		u.Email = *opts.Email
+	}
+	if opts.Username != nil {
+		u.Username = *opts.Username
+	}
+	if opts.Name != nil {
+		u.Name = *opts.Name
+	}
+	if opts.Bio != nil {
+		u.Bio = *opts.Bio
+	}
+	if opts.Admin != nil {
+		u.IsAdmin = *opts.Admin
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (s *Server) setUserState(w http.ResponseWriter, u *tgit.User, state string) {
+	if !s.requireAdmin(w) {
+		return
+	}
+	u.State = state
+	writeJSON(w, http.StatusOK, true)
+}
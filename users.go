@@ -1,7 +1,6 @@
 package tgit
 
 import (
-	"fmt"
 	"net/http"
 	"strings"
 )
@@ -25,11 +24,11 @@ type User struct {
 // Get fetches a user. Passing the empty string will fetch the authenticated user.
 // tgit doc: https://code.tencent.com/help/api/user
 func (s *UsersService) Get(user string) (*User, *Response, error) {
-	url := "user"
+	url := newRoute("user")
 	if strings.TrimSpace(user) != "" {
-		url = fmt.Sprintf("users/%s", strings.TrimSpace(user))
+		url = newRoute("users/%s", pathEscape(strings.TrimSpace(user)))
 	}
-	req, err := s.client.NewRequest(http.MethodGet, url, nil)
+	req, err := s.client.newRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -42,3 +41,150 @@ func (s *UsersService) Get(user string) (*User, *Response, error) {
 
 	return p, resp, nil
 }
+
+type ListUsersOptions struct {
+	ListOptions
+	Search   *string `url:"search,omitempty" json:"search,omitempty"`
+	Username *string `url:"username,omitempty" json:"username,omitempty"`
+	Email    *string `url:"email,omitempty" json:"email,omitempty"`
+	State    *string `url:"state,omitempty" json:"state,omitempty"`
+}
+
+// ListUsers https://code.tencent.com/help/api/user#listUsers
+func (s *UsersService) ListUsers(opts *ListUsersOptions) ([]*User, *Response, error) {
+	req, err := s.client.newRequest(http.MethodGet, newRoute("users"), opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var u []*User
+	resp, err := s.client.Do(req, &u)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return u, resp, err
+}
+
+// GetUserByID https://code.tencent.com/help/api/user#getUser
+func (s *UsersService) GetUserByID(uid int64) (*User, *Response, error) {
+	u := newRoute("users/%d", uid)
+
+	req, err := s.client.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	usr := new(User)
+	resp, err := s.client.Do(req, usr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return usr, resp, err
+}
+
+type Email struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
+}
+
+// ListEmails lists the emails of the authenticated user.
+// tgit doc: https://code.tencent.com/help/api/user#listEmails
+func (s *UsersService) ListEmails() ([]*Email, *Response, error) {
+	req, err := s.client.newRequest(http.MethodGet, newRoute("user/emails"), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var e []*Email
+	resp, err := s.client.Do(req, &e)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return e, resp, err
+}
+
+type CreateUserOptions struct {
+	Email     *string `url:"email,omitempty" json:"email,omitempty"`
+	Password  *string `url:"password,omitempty" json:"password,omitempty"`
+	Username  *string `url:"username,omitempty" json:"username,omitempty"`
+	Name      *string `url:"name,omitempty" json:"name,omitempty"`
+	Bio       *string `url:"bio,omitempty" json:"bio,omitempty"`
+	Admin     *bool   `url:"admin,omitempty" json:"admin,omitempty"`
+	CanCreate *bool   `url:"can_create_group,omitempty" json:"can_create_group,omitempty"`
+	Confirm   *bool   `url:"confirm,omitempty" json:"confirm,omitempty"`
+}
+
+// CreateUser creates a user, admin only.
+// tgit doc: https://code.tencent.com/help/api/user#createUser
+func (s *UsersService) CreateUser(opts *CreateUserOptions) (*User, *Response, error) {
+	req, err := s.client.newRequest(http.MethodPost, newRoute("users"), opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	usr := new(User)
+	resp, err := s.client.Do(req, usr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return usr, resp, err
+}
+
+type ModifyUserOptions struct {
+	Email     *string `url:"email,omitempty" json:"email,omitempty"`
+	Password  *string `url:"password,omitempty" json:"password,omitempty"`
+	Username  *string `url:"username,omitempty" json:"username,omitempty"`
+	Name      *string `url:"name,omitempty" json:"name,omitempty"`
+	Bio       *string `url:"bio,omitempty" json:"bio,omitempty"`
+	Admin     *bool   `url:"admin,omitempty" json:"admin,omitempty"`
+	CanCreate *bool   `url:"can_create_group,omitempty" json:"can_create_group,omitempty"`
+}
+
+// ModifyUser modifies a user, admin only.
+// tgit doc: https://code.tencent.com/help/api/user#modifyUser
+func (s *UsersService) ModifyUser(uid int64, opts *ModifyUserOptions) (*User, *Response, error) {
+	u := newRoute("users/%d", uid)
+
+	req, err := s.client.newRequest(http.MethodPut, u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	usr := new(User)
+	resp, err := s.client.Do(req, usr)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return usr, resp, err
+}
+
+// BlockUser blocks a user, admin only.
+// tgit doc: https://code.tencent.com/help/api/user#blockUser
+func (s *UsersService) BlockUser(uid int64) (*Response, error) {
+	u := newRoute("users/%d/block", uid)
+
+	req, err := s.client.newRequest(http.MethodPut, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
+
+// UnblockUser unblocks a user, admin only.
+// tgit doc: https://code.tencent.com/help/api/user#unblockUser
+func (s *UsersService) UnblockUser(uid int64) (*Response, error) {
+	u := newRoute("users/%d/unblock", uid)
+
+	req, err := s.client.newRequest(http.MethodPut, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req, nil)
+}
@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	tgit "github.com/liwenqiu/go-tgit"
+)
+
+type EventType string
+
+const (
+	EventTypePush         EventType = "Push Hook"
+	EventTypeTagPush      EventType = "Tag Push Hook"
+	EventTypeMergeRequest EventType = "Merge Request Hook"
+	EventTypeIssue        EventType = "Issue Hook"
+	EventTypeNote         EventType = "Note Hook"
+	EventTypeReview       EventType = "Review Hook"
+)
+
+// objectKinds maps the object_kind field of a payload to its event type, it
+// is used when the event header is missing.
+var objectKinds = map[string]EventType{
+	"push":          EventTypePush,
+	"tag_push":      EventTypeTagPush,
+	"merge_request": EventTypeMergeRequest,
+	"issue":         EventTypeIssue,
+	"note":          EventTypeNote,
+	"review":        EventTypeReview,
+}
+
+type Repository struct {
+	Name            string                    `json:"name"`
+	Description     string                    `json:"description"`
+	Homepage        string                    `json:"homepage"`
+	URL             string                    `json:"url"`
+	GitHTTPURL      string                    `json:"git_http_url"`
+	GitSSHURL       string                    `json:"git_ssh_url"`
+	VisibilityLevel tgit.VisibilityLevelValue `json:"visibility_level"`
+}
+
+type CommitAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type PushCommit struct {
+	ID        string        `json:"id"`
+	Message   string        `json:"message"`
+	Timestamp *tgit.Time    `json:"timestamp"`
+	URL       string        `json:"url"`
+	Author    *CommitAuthor `json:"author"`
+	Added     []string      `json:"added"`
+	Modified  []string      `json:"modified"`
+	Removed   []string      `json:"removed"`
+}
+
+type PushEvent struct {
+	ObjectKind        string        `json:"object_kind"`
+	OperationKind     string        `json:"operation_kind"`
+	ActionKind        string        `json:"action_kind"`
+	Before            string        `json:"before"`
+	After             string        `json:"after"`
+	Ref               string        `json:"ref"`
+	CheckoutSHA       string        `json:"checkout_sha"`
+	UserID            int64         `json:"user_id"`
+	UserName          string        `json:"user_name"`
+	UserEmail         string        `json:"user_email"`
+	ProjectID         int64         `json:"project_id"`
+	Repository        *Repository   `json:"repository"`
+	Commits           []*PushCommit `json:"commits"`
+	TotalCommitsCount int           `json:"total_commits_count"`
+}
+
+func (e PushEvent) String() string {
+	return tgit.Stringify(e)
+}
+
+// TagPushEvent shares the payload layout of a push, only the object kind and
+// the ref namespace differ.
+type TagPushEvent struct {
+	PushEvent
+	Message string `json:"message"`
+}
+
+func (e TagPushEvent) String() string {
+	return tgit.Stringify(e)
+}
+
+type MergeRequestAttributes struct {
+	tgit.MergeRequest
+	Action          string `json:"action"`
+	ExtensionAction string `json:"extension_action"`
+	URL             string `json:"url"`
+}
+
+type MergeRequestEvent struct {
+	ObjectKind       string                  `json:"object_kind"`
+	User             *tgit.User              `json:"user"`
+	ObjectAttributes *MergeRequestAttributes `json:"object_attributes"`
+}
+
+func (e MergeRequestEvent) String() string {
+	return tgit.Stringify(e)
+}
+
+type IssueAttributes struct {
+	ID          int64      `json:"id"`
+	Title       string     `json:"title"`
+	AssigneeID  int64      `json:"assignee_id"`
+	AuthorID    int64      `json:"author_id"`
+	ProjectID   int64      `json:"project_id"`
+	CreatedAt   *tgit.Time `json:"created_at"`
+	UpdatedAt   *tgit.Time `json:"updated_at"`
+	Position    int        `json:"position"`
+	BranchName  string     `json:"branch_name"`
+	Description string     `json:"description"`
+	MilestoneID int64      `json:"milestone_id"`
+	State       string     `json:"state"`
+	Iid         int64      `json:"iid"`
+	URL         string     `json:"url"`
+	Action      string     `json:"action"`
+}
+
+type IssueEvent struct {
+	ObjectKind       string           `json:"object_kind"`
+	User             *tgit.User       `json:"user"`
+	ObjectAttributes *IssueAttributes `json:"object_attributes"`
+}
+
+func (e IssueEvent) String() string {
+	return tgit.Stringify(e)
+}
+
+type NoteAttributes struct {
+	ID           int64      `json:"id"`
+	Note         string     `json:"note"`
+	NoteableType string     `json:"noteable_type"`
+	AuthorID     int64      `json:"author_id"`
+	CreatedAt    *tgit.Time `json:"created_at"`
+	UpdatedAt    *tgit.Time `json:"updated_at"`
+	ProjectID    int64      `json:"project_id"`
+	Attachment   string     `json:"attachment"`
+	LineCode     string     `json:"line_code"`
+	CommitID     string     `json:"commit_id"`
+	NoteableID   int64      `json:"noteable_id"`
+	System       bool       `json:"system"`
+	URL          string     `json:"url"`
+}
+
+type NoteEvent struct {
+	ObjectKind       string             `json:"object_kind"`
+	User             *tgit.User         `json:"user"`
+	ProjectID        int64              `json:"project_id"`
+	Repository       *Repository        `json:"repository"`
+	ObjectAttributes *NoteAttributes    `json:"object_attributes"`
+	Commit           *tgit.Commit       `json:"commit"`
+	MergeRequest     *tgit.MergeRequest `json:"merge_request"`
+	Issue            *IssueAttributes   `json:"issue"`
+}
+
+func (e NoteEvent) String() string {
+	return tgit.Stringify(e)
+}
+
+type Reviewer struct {
+	Reviewer    *tgit.User `json:"reviewer"`
+	ID          int64      `json:"id"`
+	Type        string     `json:"type"`
+	State       string     `json:"state"`
+	ProjectID   int64      `json:"project_id"`
+	CreatedAt   *tgit.Time `json:"created_at"`
+	UpdatedAt   *tgit.Time `json:"updated_at"`
+	ReviewerID  int64      `json:"reviewer_id"`
+	ReviewState string     `json:"review_state"`
+}
+
+type ReviewEvent struct {
+	ObjectKind     string      `json:"object_kind"`
+	ProjectID      int64       `json:"project_id"`
+	Author         *tgit.User  `json:"author"`
+	Reviewers      []*Reviewer `json:"reviewers"`
+	ID             int64       `json:"id"`
+	Iid            int64       `json:"iid"`
+	Event          string      `json:"event"`
+	State          string      `json:"state"`
+	ReviewableType string      `json:"reviewable_type"`
+	ReviewableID   int64       `json:"reviewable_id"`
+	CreatedAt      *tgit.Time  `json:"created_at"`
+	UpdatedAt      *tgit.Time  `json:"updated_at"`
+}
+
+func (e ReviewEvent) String() string {
+	return tgit.Stringify(e)
+}
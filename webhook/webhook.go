@@ -0,0 +1,230 @@
+// Package webhook receives and decodes TGit webhook deliveries.
+//
+// tgit doc: https://code.tencent.com/help/webhooks
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	eventHeader = "X-Event"
+	tokenHeader = "X-Token"
+
+	// DefaultMaxPayloadSize bounds the payloads accepted by a Handler.
+	DefaultMaxPayloadSize = 25 << 20
+)
+
+var (
+	ErrInvalidToken     = errors.New("webhook: invalid secret token")
+	ErrUnknownEventType = errors.New("webhook: unknown event type")
+)
+
+// ParseEvent decodes payload into the typed event matching eventType. When
+// eventType is empty the object_kind field of the payload is used instead.
+func ParseEvent(eventType EventType, payload []byte) (interface{}, error) {
+	if eventType == "" {
+		var kind struct {
+			ObjectKind string `json:"object_kind"`
+		}
+		if err := json.Unmarshal(payload, &kind); err != nil {
+			return nil, err
+		}
+		eventType = objectKinds[kind.ObjectKind]
+	}
+
+	var event interface{}
+	switch eventType {
+	case EventTypePush:
+		event = new(PushEvent)
+	case EventTypeTagPush:
+		event = new(TagPushEvent)
+	case EventTypeMergeRequest:
+		event = new(MergeRequestEvent)
+	case EventTypeIssue:
+		event = new(IssueEvent)
+	case EventTypeNote:
+		event = new(NoteEvent)
+	case EventTypeReview:
+		event = new(ReviewEvent)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEventType, eventType)
+	}
+
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// Handler is an http.Handler that validates, decodes and dispatches webhook
+// deliveries to the registered callbacks. Callbacks must be registered before
+// the handler starts serving.
+type Handler struct {
+	secret     string
+	maxPayload int64
+
+	push         []func(*PushEvent) error
+	tagPush      []func(*TagPushEvent) error
+	mergeRequest []func(*MergeRequestEvent) error
+	issue        []func(*IssueEvent) error
+	note         []func(*NoteEvent) error
+	review       []func(*ReviewEvent) error
+	unknown      func(EventType, []byte) error
+}
+
+// NewHandler returns a Handler that only accepts deliveries carrying secret in
+// the token header. An empty secret disables the check.
+func NewHandler(secret string) *Handler {
+	return &Handler{secret: secret, maxPayload: DefaultMaxPayloadSize}
+}
+
+// SetMaxPayloadSize changes the largest payload accepted, in bytes. Larger
+// deliveries are rejected with 413 Request Entity Too Large.
+func (h *Handler) SetMaxPayloadSize(n int64) {
+	h.maxPayload = n
+}
+
+func (h *Handler) OnPush(fn func(*PushEvent) error) {
+	h.push = append(h.push, fn)
+}
+
+func (h *Handler) OnTagPush(fn func(*TagPushEvent) error) {
+	h.tagPush = append(h.tagPush, fn)
+}
+
+func (h *Handler) OnMergeRequest(fn func(*MergeRequestEvent) error) {
+	h.mergeRequest = append(h.mergeRequest, fn)
+}
+
+func (h *Handler) OnIssue(fn func(*IssueEvent) error) {
+	h.issue = append(h.issue, fn)
+}
+
+func (h *Handler) OnNote(fn func(*NoteEvent) error) {
+	h.note = append(h.note, fn)
+}
+
+func (h *Handler) OnReview(fn func(*ReviewEvent) error) {
+	h.review = append(h.review, fn)
+}
+
+// OnUnknown sets the callback receiving the raw payload of event types this
+// package does not decode. Without one they are acknowledged and dropped, so
+// TGit does not record them as failed deliveries.
+func (h *Handler) OnUnknown(fn func(eventType EventType, payload []byte) error) {
+	h.unknown = fn
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Check the token before reading anything from an unauthenticated sender.
+	if err := h.validate(r); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxPayload))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	eventType := EventType(r.Header.Get(eventHeader))
+	event, err := ParseEvent(eventType, payload)
+	if errors.Is(err, ErrUnknownEventType) {
+		if h.unknown != nil {
+			err = h.unknown(eventType, payload)
+		} else {
+			err = nil
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.dispatch(event); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) validate(r *http.Request) error {
+	if h.secret == "" {
+		return nil
+	}
+
+	token := r.Header.Get(tokenHeader)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.secret)) != 1 {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+func (h *Handler) dispatch(event interface{}) error {
+	switch e := event.(type) {
+	case *PushEvent:
+		for _, fn := range h.push {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+	case *TagPushEvent:
+		for _, fn := range h.tagPush {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+	case *MergeRequestEvent:
+		for _, fn := range h.mergeRequest {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+	case *IssueEvent:
+		for _, fn := range h.issue {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+	case *NoteEvent:
+		for _, fn := range h.note {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+	case *ReviewEvent:
+		for _, fn := range h.review {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}